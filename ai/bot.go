@@ -0,0 +1,384 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultBotUsername    = "support-bot"
+	defaultBotConcurrency = 4
+)
+
+// Bot answers users from inside the server. It follows the hub's message
+// events rather than holding WebSocket connections, and writes its answers
+// through the store as a user with the system role, which has no password
+// and cannot log in. All personas share that user; each chat is answered
+// with the prompt, knowledge and model of the persona it is matched to.
+type Bot struct {
+	config      util.Config
+	pool        *pgxpool.Pool
+	store       *db.SQLStore
+	hub         *ws.Hub
+	vectorStore VectorStore
+	completer   CompletionProvider
+	retriever   *Retriever
+	departments *DepartmentClassifier
+	languages   languagePolicy
+	handoffs    handoffPolicy
+	contexts    *ContextBuilder
+	tools       []tool
+
+	modelsMu sync.Mutex
+	models   map[string]botModel
+
+	// queues holds the messages waiting in each chat that has a worker
+	// running; slots caps how many answers are generated at once.
+	queueMu sync.Mutex
+	queues  map[uuid.UUID][]ws.MessageEvent
+	slots   chan struct{}
+}
+
+func NewBot(config util.Config, pool *pgxpool.Pool, store *db.SQLStore, hub *ws.Hub, vectorStore VectorStore, embedder EmbeddingProvider) (*Bot, error) {
+	completer, err := NewCompletionProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create completion provider: %w", err)
+	}
+	tools, err := newToolset(config.BotTools)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := config.BotConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBotConcurrency
+	}
+	return &Bot{
+		config:      config,
+		pool:        pool,
+		store:       store,
+		hub:         hub,
+		vectorStore: vectorStore,
+		completer:   completer,
+		retriever:   NewRetriever(config, embedder, vectorStore),
+		departments: NewDepartmentClassifier(store),
+		languages:   newLanguagePolicy(config),
+		handoffs:    newHandoffPolicy(config),
+		contexts:    NewContextBuilder(config, completer.Model()),
+		tools:       tools,
+		models:      make(map[string]botModel),
+		queues:      make(map[uuid.UUID][]ws.MessageEvent),
+		slots:       make(chan struct{}, concurrency),
+	}, nil
+}
+
+// Start answers user messages until ctx is done. The hub must be running.
+func (b *Bot) Start(ctx context.Context) {
+	botID, err := b.ensureBotUser(ctx)
+	if err != nil {
+		fmt.Printf("Cannot set up the bot user: %v\n", err)
+		return
+	}
+	events := b.hub.Subscribe()
+	fmt.Printf("Bot %s listening for messages\n", botID)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			if !fromChatUser(event) || event.SenderExternalID == botID || strings.TrimSpace(event.Content) == "" {
+				continue
+			}
+			b.enqueue(ctx, botID, event)
+		}
+	}
+}
+
+// enqueue queues event behind the chat's earlier messages. Each chat gets
+// one worker, so its messages are answered in order and every answer sees
+// the ones before it.
+func (b *Bot) enqueue(ctx context.Context, botID uuid.UUID, event ws.MessageEvent) {
+	b.queueMu.Lock()
+	queue, running := b.queues[event.ChatExternalID]
+	b.queues[event.ChatExternalID] = append(queue, event)
+	b.queueMu.Unlock()
+	if !running {
+		go b.work(ctx, botID, event.ChatExternalID)
+	}
+}
+
+// work answers the chat's queued messages one by one and stops once the
+// queue is empty. It waits for a free slot before each answer.
+func (b *Bot) work(ctx context.Context, botID, chatID uuid.UUID) {
+	for {
+		b.queueMu.Lock()
+		queue := b.queues[chatID]
+		if len(queue) == 0 {
+			delete(b.queues, chatID)
+			b.queueMu.Unlock()
+			return
+		}
+		event := queue[0]
+		b.queues[chatID] = queue[1:]
+		b.queueMu.Unlock()
+
+		select {
+		case b.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		b.answer(ctx, botID, event)
+		<-b.slots
+	}
+}
+
+// fromChatUser reports whether the event is a message the bot should answer:
+// one from the person who opened the chat, not from staff or the system.
+func fromChatUser(event ws.MessageEvent) bool {
+	switch db.RoleType(event.SenderRole) {
+	case db.RoleTypeAdmin, db.RoleTypeSuperadmin, db.RoleTypeSystem:
+		return false
+	}
+	return !event.IsSystemMessage && !event.IsAdminMessage
+}
+
+// ensureBotUser returns the bot's user, creating it on first start. An
+// account left over from when the bot logged in like an admin is turned
+// into a system user and loses its password.
+func (b *Bot) ensureBotUser(ctx context.Context) (uuid.UUID, error) {
+	username := b.config.BotUsername
+	if username == "" {
+		username = defaultBotUsername
+	}
+	user, err := b.store.Querier.GetUserByUsername(ctx, pgtype.Text{String: username, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		created, err := b.store.Querier.CreateUser(ctx, db.CreateUserParams{
+			FirstName: "Support",
+			LastName:  "Bot",
+			Username:  pgtype.Text{String: username, Valid: true},
+			Email:     username + "@bot.invalid",
+			Role:      string(db.RoleTypeSystem),
+			Status:    db.AccountStatusVerified,
+			Photos:    []string{},
+		})
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return created.UserExternalID, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if user.Role != string(db.RoleTypeSystem) {
+		_, err := b.store.Querier.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+			UserExternalID: user.UserExternalID,
+			Role:           string(db.RoleTypeSystem),
+		})
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+	if user.HashedPassword.Valid {
+		err := b.store.Querier.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+			UserExternalID: user.UserExternalID,
+		})
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+	return user.UserExternalID, nil
+}
+
+// answer replies to one user message, or hands the chat to a person.
+func (b *Bot) answer(ctx context.Context, botID uuid.UUID, event ws.MessageEvent) {
+	chatID := event.ChatExternalID
+	userMsg := event.Content
+	chat, err := b.store.Querier.GetChat(ctx, chatID)
+	if err != nil {
+		fmt.Printf("Cannot load chat %s: %v\n", chatID, err)
+		return
+	}
+	if !botMayReply(chat) {
+		return
+	}
+	fmt.Printf("Answering message %s in %s\n", event.MessageExternalID, chatID)
+
+	history, err := b.loadHistory(ctx, botID, event)
+	if err != nil {
+		fmt.Printf("Error loading history for %s: %v\n", chatID, err)
+	}
+	lang := b.languages.replyLanguage(userMsg, history)
+	department := b.chatDepartment(ctx, chat, userMsg)
+	p := b.chatPersona(ctx, chat, department)
+	if p.handoffs.wantsHuman(userMsg) {
+		if err := b.handOff(ctx, chat, botID, lang, handoff{reason: HandoffReasonUserRequest, detail: userMsg}); err != nil {
+			fmt.Printf("Error handing off %s: %v\n", chatID, err)
+		}
+		return
+	}
+
+	query := b.rewriteQuery(ctx, p, history, userMsg)
+	filter := SearchFilter{
+		Department:  department,
+		Language:    lang,
+		Departments: p.departments,
+		Sources:     p.sources,
+	}
+
+	found, err := b.retrieve(ctx, query, p.model.contexts.TopK(), filter)
+	if err != nil {
+		fmt.Printf("Error retrieving chunks: %v\n", err)
+		b.postFallback(ctx, chatID, botID, lang)
+		return
+	}
+	if detail, low := p.handoffs.lowConfidence(found); low {
+		h := handoff{
+			reason:     HandoffReasonLowConfidence,
+			detail:     detail,
+			similarity: pgtype.Float8{Float64: found.TopSimilarity, Valid: len(found.Chunks) > 0},
+		}
+		if err := b.handOff(ctx, chat, botID, lang, h); err != nil {
+			fmt.Printf("Error handing off %s: %v\n", chatID, err)
+		}
+		return
+	}
+
+	packed, templateID := b.prompt(ctx, chat, p, userMsg, found.Chunks, history, lang)
+	env := toolEnv{chat: chat, department: department, botID: botID, lang: lang}
+	if err := b.streamReply(ctx, env, p, packed.Messages, packed.Chunks, templateID); err != nil {
+		fmt.Printf("Error streaming reply in %s: %v\n", chatID, err)
+		b.postFallback(ctx, chatID, botID, lang)
+		return
+	}
+	fmt.Printf(" Bot replied in %s\n", chatID)
+}
+
+// retrieve prefers chunks in the reply language but falls back to any
+// language, since a document that only exists in Persian still answers an
+// English question.
+func (b *Bot) retrieve(ctx context.Context, query string, topK int, filter SearchFilter) (Retrieval, error) {
+	return retrievePreferringLanguage(ctx, b.retriever, query, topK, filter)
+}
+
+func retrievePreferringLanguage(ctx context.Context, r *Retriever, query string, topK int, filter SearchFilter) (Retrieval, error) {
+	found, err := r.Retrieve(ctx, query, topK, filter)
+	if err != nil || len(found.Chunks) > 0 || filter.Language == "" {
+		return found, err
+	}
+	filter.Language = ""
+	return r.Retrieve(ctx, query, topK, filter)
+}
+
+// streamReply pushes the answer to the chat room piece by piece and stores
+// the final text as a single message, citing sources and the prompt template
+// version, once the provider is done. Frames carry the persona's name and
+// avatar so the client can show who is answering. When a tool hands the chat
+// to a person the stream ends without an answer.
+func (b *Bot) streamReply(ctx context.Context, env toolEnv, p persona, messages []ChatMessage, sources []Chunk, templateID uuid.UUID) error {
+	chatID, botID := env.chat.ChatExternalID, env.botID
+	streamID := uuid.NewString()
+	send := func(frame ws.StreamFrame) {
+		frame.StreamID = streamID
+		frame.ChatExternalID = chatID
+		frame.SenderExternalID = botID
+		frame.SenderName = p.name
+		frame.SenderAvatarURL = p.avatarURL
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return
+		}
+		b.hub.Broadcast <- ws.BroadcastMessage{
+			ChatExternalID: chatID,
+			Data:           data,
+		}
+	}
+
+	resp, handedOff, err := b.complete(ctx, env, p, messages, func(delta string) error {
+		send(ws.StreamFrame{Type: ws.FrameTypeDelta, Delta: delta})
+		return nil
+	})
+	if err != nil {
+		send(ws.StreamFrame{Type: ws.FrameTypeFailed, Error: "could not generate an answer"})
+		return err
+	}
+	if handedOff {
+		send(ws.StreamFrame{Type: ws.FrameTypeEnded})
+		return nil
+	}
+
+	result, err := b.store.CreateBotMessageTx(ctx, db.CreateBotMessageTxParams{
+		CreateMessageParams: db.CreateMessageParams{
+			ChatExternalID:   chatID,
+			SenderExternalID: botID,
+			Content:          resp.Content,
+			IsSystemMessage:  false,
+			IsAdminMessage:   false,
+		},
+		Citations:        citationParams(sources),
+		PromptTemplateID: pgtype.UUID{Bytes: templateID, Valid: templateID != uuid.Nil},
+	})
+	if err != nil {
+		send(ws.StreamFrame{Type: ws.FrameTypeFailed, Error: "could not save the answer"})
+		return err
+	}
+
+	msg := result.Message
+	send(ws.StreamFrame{
+		Type:              ws.FrameTypeCompleted,
+		Content:           msg.Content,
+		MessageExternalID: &msg.MessageExternalID,
+		CreatedAt:         msg.CreatedAt.Time.Format(time.RFC3339),
+		Citations:         Citations(sources),
+	})
+	return nil
+}
+
+// citationParams numbers sources the same way promptVars labels them, so
+// "Context 2" in the prompt is citation position 2.
+func citationParams(sources []Chunk) []db.CreateMessageCitationParams {
+	params := make([]db.CreateMessageCitationParams, 0, len(sources))
+	for i, c := range sources {
+		params = append(params, db.CreateMessageCitationParams{
+			ChunkExternalID: pgtype.UUID{Bytes: c.ID, Valid: c.ID != uuid.Nil},
+			SourceID:        pgtype.UUID{Bytes: c.SourceID, Valid: c.SourceID != uuid.Nil},
+			SourceFilename:  pgtype.Text{String: c.SourceFilename, Valid: c.SourceFilename != ""},
+			SourcePage:      pgtype.Int4{Int32: int32(c.SourcePage), Valid: c.SourcePage > 0},
+			Position:        int32(i + 1),
+		})
+	}
+	return params
+}
+
+// Citations describes sources the way clients see them, numbered like
+// citationParams.
+func Citations(sources []Chunk) []ws.Citation {
+	citations := make([]ws.Citation, 0, len(sources))
+	for i, c := range sources {
+		citation := ws.Citation{
+			SourceFilename: c.SourceFilename,
+			SourcePage:     int32(c.SourcePage),
+			Position:       int32(i + 1),
+		}
+		if c.ID != uuid.Nil {
+			citation.ChunkExternalID = c.ID.String()
+		}
+		if c.SourceID != uuid.Nil {
+			citation.SourceID = c.SourceID.String()
+		}
+		citations = append(citations, citation)
+	}
+	return citations
+}
@@ -0,0 +1,335 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultContextTokens = 8192
+	defaultAnswerTokens  = 1024
+	defaultRetrievalTopK = 8
+
+	// Chat formats wrap every message in a few tokens of markup, and the
+	// reply is primed with a few more.
+	tokensPerMessage = 4
+	tokensPerReply   = 3
+
+	// historyShare is the part of the room history may take before chunks
+	// are packed, 1/historyShare; whatever chunks leave over goes to older
+	// turns afterwards.
+	historyShare = 4
+
+	// minOverlapRunes is the shortest shared text that counts as the splitter
+	// overlap between neighbouring chunks rather than a coincidence.
+	minOverlapRunes = 20
+	// nearDuplicateShare is how much of a chunk's wording may already be in
+	// the context before it is dropped as a near duplicate.
+	nearDuplicateShare = 0.8
+)
+
+// modelContextWindows are the context sizes of common models. Versioned
+// names such as "gpt-4o-2024-08-06" or "llama3.1:8b" match by prefix.
+var modelContextWindows = map[string]int{
+	"gpt-4.1":       1047576,
+	"gpt-4o":        128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"llama3.1":      131072,
+	"llama3.2":      131072,
+	"llama3":        8192,
+	"mistral":       32768,
+	"qwen2.5":       32768,
+	"gemma2":        8192,
+}
+
+// contextWindow returns the window of model, or 0 when it is unknown.
+func contextWindow(model string) int {
+	model = strings.ToLower(model)
+	best, window := "", 0
+	for name, size := range modelContextWindows {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best, window = name, size
+		}
+	}
+	return window
+}
+
+// contextBudget is how many tokens the prompt and the answer may use
+// together: AI_CONTEXT_TOKENS, never more than the model's window.
+func contextBudget(config util.Config, model string) int {
+	budget := config.AIContextTokens
+	if budget <= 0 {
+		budget = defaultContextTokens
+	}
+	if window := contextWindow(model); window > 0 && budget > window {
+		budget = window
+	}
+	return budget
+}
+
+func answerTokens(config util.Config, budget int) int {
+	answer := config.AIMaxAnswerTokens
+	if answer <= 0 {
+		answer = defaultAnswerTokens
+	}
+	if answer > budget/2 {
+		answer = budget / 2
+	}
+	return answer
+}
+
+// ContextBuilder fits a prompt into the model's budget. Tokens are counted
+// with approxTokens, the estimate the splitter sizes chunks with, plus the
+// per-message markup of chat formats; no vocabulary is shipped, so the room
+// reserved for the answer doubles as the safety margin.
+type ContextBuilder struct {
+	budget int
+	answer int
+	topK   int
+	count  func(string) int
+}
+
+func NewContextBuilder(config util.Config, model string) *ContextBuilder {
+	budget := contextBudget(config, model)
+	topK := config.RetrievalTopK
+	if topK <= 0 {
+		topK = defaultRetrievalTopK
+	}
+	return &ContextBuilder{
+		budget: budget,
+		answer: answerTokens(config, budget),
+		topK:   topK,
+		count:  approxTokens,
+	}
+}
+
+// TopK is how many chunks to retrieve for Pack to choose from.
+func (cb *ContextBuilder) TopK() int {
+	return cb.topK
+}
+
+// AnswerTokens is the room kept free for the answer; pass it as the
+// completion's MaxTokens.
+func (cb *ContextBuilder) AnswerTokens() int {
+	return cb.answer
+}
+
+// PackedContext is a prompt that fits the budget, with what went into it.
+// Chunks are the ones the prompt numbers as "Context 1", "Context 2" and so
+// on, possibly with text shared with an earlier chunk cut out.
+type PackedContext struct {
+	Messages     []ChatMessage
+	Chunks       []Chunk
+	History      []ChatMessage
+	Tokens       int
+	Budget       int
+	AnswerTokens int
+	Candidates   int
+	Duplicates   int
+	Turns        int
+}
+
+// String describes the packing for the logs.
+func (p PackedContext) String() string {
+	labels := make([]string, 0, len(p.Chunks))
+	for _, c := range p.Chunks {
+		labels = append(labels, fmt.Sprintf("%s [%s]", c.sourceLabel(), c.ID))
+	}
+	return fmt.Sprintf("%d of %d tokens (%d kept for the answer), %d of %d chunks (%d duplicates), %d of %d history turns; chunks: %s",
+		p.Tokens, p.Budget, p.AnswerTokens, len(p.Chunks), p.Candidates, p.Duplicates, len(p.History), p.Turns, strings.Join(labels, ", "))
+}
+
+// Pack renders t with as much of chunks and history as fits. The system
+// prompt and the question always go in. Chunks are taken in the order given,
+// most relevant first, after duplicates and overlaps are removed; history is
+// taken newest first, so it is the oldest turns that are left out.
+func (cb *ContextBuilder) Pack(t PromptTemplate, vars PromptVars, chunks []Chunk, history []ChatMessage) (PackedContext, error) {
+	packed := PackedContext{Budget: cb.budget, AnswerTokens: cb.answer, Candidates: len(chunks), Turns: len(history)}
+	chunks, packed.Duplicates = dedupChunks(chunks)
+
+	vars.Context, vars.History = "", ""
+	base, err := t.Render(vars, nil)
+	if err != nil {
+		return packed, err
+	}
+	room := cb.budget - cb.answer - cb.countMessages(base)
+
+	inline := t.inlinesHistory()
+	turnCost := func(m ChatMessage) int {
+		if inline {
+			return cb.count(transcriptLine(m))
+		}
+		return cb.count(m.Content) + tokensPerMessage
+	}
+
+	// Newest turns first, up to the history share of the room.
+	turns := 0
+	historyRoom := room / historyShare
+	for turns < len(history) {
+		cost := turnCost(history[len(history)-1-turns])
+		if cost > historyRoom {
+			break
+		}
+		historyRoom -= cost
+		room -= cost
+		turns++
+	}
+
+	for _, c := range chunks {
+		cost := cb.count(contextBlock(len(packed.Chunks)+1, c))
+		if cost > room {
+			continue
+		}
+		room -= cost
+		packed.Chunks = append(packed.Chunks, c)
+	}
+
+	// Older turns get whatever the chunks left.
+	for turns < len(history) {
+		cost := turnCost(history[len(history)-1-turns])
+		if cost > room {
+			break
+		}
+		room -= cost
+		turns++
+	}
+	packed.History = history[len(history)-turns:]
+
+	vars.Context = contextText(packed.Chunks)
+	vars.History = transcript(packed.History)
+	packed.Messages, err = t.Render(vars, packed.History)
+	if err != nil {
+		return packed, err
+	}
+	packed.Tokens = cb.countMessages(packed.Messages)
+	return packed, nil
+}
+
+func (cb *ContextBuilder) countMessages(messages []ChatMessage) int {
+	total := tokensPerReply
+	for _, m := range messages {
+		total += cb.count(m.Content) + tokensPerMessage
+	}
+	return total
+}
+
+// dedupChunks drops chunks whose text is already covered by a more relevant
+// one and cuts the overlap the splitter leaves between neighbouring chunks
+// of the same document, returning the rest and how many were dropped.
+func dedupChunks(chunks []Chunk) ([]Chunk, int) {
+	var (
+		kept     []Chunk
+		shingles = make(map[string]bool)
+		dropped  int
+	)
+	for _, c := range chunks {
+		text := strings.TrimSpace(c.Text)
+		original := text
+		duplicate := text == ""
+		for _, k := range kept {
+			if (c.Hash != "" && c.Hash == k.Hash) || strings.Contains(k.Text, text) {
+				duplicate = true
+				break
+			}
+			if sameSource(c, k) {
+				text = strings.TrimSpace(trimOverlap(k.Text, text))
+			}
+		}
+		words := wordShingles(text)
+		if !duplicate && len(words) > 0 {
+			seen := 0
+			for _, w := range words {
+				if shingles[w] {
+					seen++
+				}
+			}
+			duplicate = float64(seen)/float64(len(words)) >= nearDuplicateShare
+		}
+		// What is left of a trimmed chunk may be a stray fragment.
+		if duplicate || (text != original && runeCount(text) < minOverlapRunes) {
+			dropped++
+			continue
+		}
+		for _, w := range words {
+			shingles[w] = true
+		}
+		c.Text = text
+		kept = append(kept, c)
+	}
+	return kept, dropped
+}
+
+func sameSource(a, b Chunk) bool {
+	return a.SourceID == b.SourceID && a.SourceFilename == b.SourceFilename
+}
+
+// trimOverlap removes from next the text it shares with the end or the start
+// of prev, as left by the splitter's overlap.
+func trimOverlap(prev, next string) string {
+	if n := overlapLen(prev, next); n > 0 {
+		return next[n:]
+	}
+	if n := overlapLen(next, prev); n > 0 {
+		return next[:len(next)-n]
+	}
+	return next
+}
+
+// overlapLen is the length of the longest suffix of a that is also a prefix
+// of b, if it is at least minOverlapRunes long.
+func overlapLen(a, b string) int {
+	probe := []rune(b)
+	if len(probe) < minOverlapRunes {
+		return 0
+	}
+	head := string(probe[:minOverlapRunes])
+	for from := 0; from < len(a); {
+		i := strings.Index(a[from:], head)
+		if i < 0 {
+			return 0
+		}
+		if tail := a[from+i:]; strings.HasPrefix(b, tail) {
+			return len(tail)
+		}
+		from += i + 1
+	}
+	return 0
+}
+
+// wordShingles returns the word triples of text, normalized for matching.
+func wordShingles(text string) []string {
+	words := strings.Fields(normalizeForMatch(text))
+	var out []string
+	for i := 0; i+3 <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+3], " "))
+	}
+	return out
+}
+
+func contextBlock(n int, c Chunk) string {
+	return fmt.Sprintf("Context %d (%s):\n%s\n\n", n, c.sourceLabel(), c.Text)
+}
+
+func contextText(chunks []Chunk) string {
+	var sources strings.Builder
+	for i, c := range chunks {
+		sources.WriteString(contextBlock(i+1, c))
+	}
+	return sources.String()
+}
+
+func transcriptLine(m ChatMessage) string {
+	return fmt.Sprintf("%s: %s\n", m.Role, m.Content)
+}
+
+func transcript(history []ChatMessage) string {
+	var out strings.Builder
+	for _, m := range history {
+		out.WriteString(transcriptLine(m))
+	}
+	return out.String()
+}
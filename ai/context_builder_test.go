@@ -0,0 +1,234 @@
+package ai
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+func TestDedupChunks(t *testing.T) {
+	const (
+		shared = "Refunds are paid back to the original card"
+		intro  = "Returns are accepted within thirty days. "
+		rest   = " within fourteen days of the return."
+	)
+	tests := []struct {
+		name        string
+		chunks      []Chunk
+		want        []string
+		wantDropped int
+	}{
+		{
+			name: "distinct chunks are kept",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: "Orders ship from Tehran."},
+				{SourceFilename: "b.md", Text: "Refunds take two weeks."},
+			},
+			want: []string{"Orders ship from Tehran.", "Refunds take two weeks."},
+		},
+		{
+			name: "same hash",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: "Orders ship from Tehran.", Hash: "h1"},
+				{SourceFilename: "b.md", Text: "Orders leave Tehran daily.", Hash: "h1"},
+			},
+			want:        []string{"Orders ship from Tehran."},
+			wantDropped: 1,
+		},
+		{
+			name: "text contained in a more relevant chunk",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: "Orders ship from Tehran. Delivery takes three days."},
+				{SourceFilename: "b.md", Text: "  Delivery takes three days. "},
+				{SourceFilename: "c.md", Text: " "},
+			},
+			want:        []string{"Orders ship from Tehran. Delivery takes three days."},
+			wantDropped: 2,
+		},
+		{
+			name: "splitter overlap with the previous chunk is cut",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: intro + shared},
+				{SourceFilename: "a.md", Text: shared + rest},
+			},
+			want: []string{intro + shared, strings.TrimSpace(rest)},
+		},
+		{
+			name: "splitter overlap with the next chunk is cut",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: shared + rest},
+				{SourceFilename: "a.md", Text: intro + shared},
+			},
+			want: []string{shared + rest, strings.TrimSpace(intro)},
+		},
+		{
+			name: "overlap is only cut within one document",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: intro + shared},
+				{SourceFilename: "b.md", Text: shared + rest},
+			},
+			want: []string{intro + shared, shared + rest},
+		},
+		{
+			name: "fragment left after cutting the overlap",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: intro + shared},
+				{SourceFilename: "a.md", Text: shared + " today."},
+			},
+			want:        []string{intro + shared},
+			wantDropped: 1,
+		},
+		{
+			name: "near duplicate wording",
+			chunks: []Chunk{
+				{SourceFilename: "a.md", Text: "Refunds are paid back to the original card within fourteen days."},
+				{SourceFilename: "b.md", Text: "refunds are paid back to the original card, within fourteen days!"},
+			},
+			want:        []string{"Refunds are paid back to the original card within fourteen days."},
+			wantDropped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped := dedupChunks(tt.chunks)
+			var got []string
+			for _, c := range kept {
+				got = append(got, c.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) || dropped != tt.wantDropped {
+				t.Errorf("dedupChunks() = %q with %d dropped, want %q with %d dropped", got, dropped, tt.want, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestContextBuilderPack(t *testing.T) {
+	tmpl := BuiltinPromptTemplate()
+	vars := promptVars("Example Co", "How long do refunds take?", nil, nil, LanguageEnglish)
+	base, err := tmpl.Render(vars, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe := &ContextBuilder{count: approxTokens}
+	baseCost := probe.countMessages(base)
+
+	long := Chunk{SourceFilename: "long.md", Text: strings.Repeat("Shipping rules differ by region and carrier. ", 40)}
+	short := Chunk{SourceFilename: "refunds.md", Text: "Refunds take fourteen days."}
+	copyOfShort := Chunk{SourceFilename: "faq.md", Text: "Refunds take fourteen days."}
+	history := []ChatMessage{
+		{Role: "user", Content: "Hello, I bought a lamp last month and it arrived broken."},
+		{Role: "assistant", Content: "Sorry to hear that. Would you like a replacement or a refund?"},
+		{Role: "user", Content: "A refund please."},
+	}
+	turnCost := func(m ChatMessage) int { return approxTokens(m.Content) + tokensPerMessage }
+	shortCost := approxTokens(contextBlock(1, short))
+
+	tests := []struct {
+		name        string
+		budget      int
+		chunks      []Chunk
+		history     []ChatMessage
+		wantChunks  []string
+		wantHistory int
+		wantDups    int
+	}{
+		{
+			name:        "everything fits",
+			budget:      defaultContextTokens,
+			chunks:      []Chunk{short, long, copyOfShort},
+			history:     history,
+			wantChunks:  []string{"refunds.md", "long.md"},
+			wantHistory: 3,
+			wantDups:    1,
+		},
+		{
+			name:       "chunk too large is skipped for a smaller one",
+			budget:     baseCost + shortCost + 1,
+			chunks:     []Chunk{long, short},
+			wantChunks: []string{"refunds.md"},
+		},
+		{
+			name:        "oldest turns are left out",
+			budget:      baseCost + turnCost(history[2]) + turnCost(history[1]) + 1,
+			history:     history,
+			wantHistory: 2,
+		},
+		{
+			name:    "system prompt and question go in over budget",
+			budget:  1,
+			chunks:  []Chunk{short},
+			history: history,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := &ContextBuilder{budget: tt.budget, count: approxTokens}
+			packed, err := cb.Pack(tmpl, vars, tt.chunks, tt.history)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, c := range packed.Chunks {
+				names = append(names, c.SourceFilename)
+			}
+			if !reflect.DeepEqual(names, tt.wantChunks) {
+				t.Errorf("chunks %v, want %v", names, tt.wantChunks)
+			}
+			if packed.Duplicates != tt.wantDups || packed.Candidates != len(tt.chunks) {
+				t.Errorf("%d of %d candidates were duplicates, want %d of %d", packed.Duplicates, packed.Candidates, tt.wantDups, len(tt.chunks))
+			}
+			if len(packed.History) != tt.wantHistory {
+				t.Fatalf("%d history turns, want %d", len(packed.History), tt.wantHistory)
+			}
+			if want := history[len(history)-tt.wantHistory:]; tt.wantHistory > 0 && !reflect.DeepEqual(packed.History, want) {
+				t.Errorf("history %v, want the newest turns %v", packed.History, want)
+			}
+			if n := len(packed.Messages); n != tt.wantHistory+2 || packed.Messages[0].Role != "system" || packed.Messages[n-1].Role != "user" {
+				t.Fatalf("messages %v, want system, %d turns and the question", packed.Messages, tt.wantHistory)
+			}
+			question := packed.Messages[len(packed.Messages)-1].Content
+			if !strings.Contains(question, vars.Question) {
+				t.Errorf("question missing from %q", question)
+			}
+			for i, name := range tt.wantChunks {
+				if label := fmt.Sprintf("Context %d (%s", i+1, name); !strings.Contains(question, label) {
+					t.Errorf("%q missing from %q", label, question)
+				}
+			}
+			if packed.Tokens != cb.countMessages(packed.Messages) {
+				t.Errorf("Tokens = %d, want %d", packed.Tokens, cb.countMessages(packed.Messages))
+			}
+			if tt.budget > 1 && packed.Tokens > tt.budget {
+				t.Errorf("%d tokens over the budget of %d", packed.Tokens, tt.budget)
+			}
+		})
+	}
+}
+
+func TestNewContextBuilder(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     util.Config
+		model      string
+		wantBudget int
+		wantAnswer int
+		wantTopK   int
+	}{
+		{"defaults", util.Config{}, "", defaultContextTokens, defaultAnswerTokens, defaultRetrievalTopK},
+		{"capped by the model window", util.Config{AIContextTokens: 32000}, "gpt-4-0613", 8192, defaultAnswerTokens, defaultRetrievalTopK},
+		{"longest prefix wins", util.Config{AIContextTokens: 32000}, "gpt-4o-mini", 32000, defaultAnswerTokens, defaultRetrievalTopK},
+		{"answer at most half", util.Config{AIContextTokens: 1000, AIMaxAnswerTokens: 800, RetrievalTopK: 3}, "llama3.1:8b", 1000, 500, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewContextBuilder(tt.config, tt.model)
+			if cb.budget != tt.wantBudget || cb.AnswerTokens() != tt.wantAnswer || cb.TopK() != tt.wantTopK {
+				t.Errorf("budget %d, answer %d, top k %d, want %d, %d and %d",
+					cb.budget, cb.AnswerTokens(), cb.TopK(), tt.wantBudget, tt.wantAnswer, tt.wantTopK)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+// DepartmentClassifier routes a chat to a department by matching the
+// keywords admins configure for each one. It is deliberately simple: a
+// department wins when more of its keywords appear in the text than any
+// other's, and a tie or no match leaves the chat unrouted.
+type DepartmentClassifier struct {
+	store *db.SQLStore
+}
+
+func NewDepartmentClassifier(store *db.SQLStore) *DepartmentClassifier {
+	return &DepartmentClassifier{store: store}
+}
+
+// Classify returns the slug of the best matching department, or "" when
+// nothing matches clearly.
+func (c *DepartmentClassifier) Classify(ctx context.Context, text string) (string, error) {
+	departments, err := c.store.Querier.ListDepartments(ctx)
+	if err != nil {
+		return "", err
+	}
+	return classifyDepartment(departments, text), nil
+}
+
+func classifyDepartment(departments []db.Department, text string) string {
+	// Keywords must start a word but may carry a suffix, so "refund" matches
+	// "refunds" and "پرداخت" matches "پرداختم", while "ship" misses "relationship".
+	text = " " + normalizeForMatch(text)
+	best, bestScore, tie := "", 0, false
+	for _, d := range departments {
+		score := 0
+		for _, kw := range append([]string{d.Slug, d.Name}, d.Keywords...) {
+			kw = normalizeForMatch(kw)
+			if kw != "" && strings.Contains(text, " "+kw) {
+				score++
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = d.Slug, score, false
+		case score == bestScore && score > 0:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
+
+// normalizeForMatch lowercases text, turns punctuation into spaces and irons
+// out the spelling variants that would otherwise make a Persian keyword miss:
+// Arabic yeh and kaf, and compounds written with a ZWNJ instead of a space.
+func normalizeForMatch(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == 'ي':
+			return 'ی'
+		case r == 'ك':
+			return 'ک'
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// chatDepartment returns the department the chat is routed to. Unrouted chats
+// are classified from their label and msg, so the first message that names
+// a topic decides where the chat goes.
+func (b *Bot) chatDepartment(ctx context.Context, chat db.Chat, msg string) string {
+	if chat.Department.Valid {
+		return chat.Department.String
+	}
+	department, err := b.departments.Classify(ctx, chat.Label+"\n"+msg)
+	if err != nil {
+		fmt.Printf("Cannot classify chat %s: %v\n", chat.ChatExternalID, err)
+		return ""
+	}
+	if department == "" {
+		return ""
+	}
+	_, err = b.store.Querier.SetChatDepartment(ctx, db.SetChatDepartmentParams{
+		ChatExternalID: chat.ChatExternalID,
+		Department:     pgtype.Text{String: department, Valid: true},
+	})
+	if err != nil {
+		fmt.Printf("Cannot route chat %s to %s: %v\n", chat.ChatExternalID, department, err)
+	}
+	return department
+}
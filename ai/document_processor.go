@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/jobs"
+	"github.com/zahra-pzk/Chatbot_Project3/storage"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	SourceStatusUploaded   = "uploaded"
+	SourceStatusProcessing = "processing"
+	SourceStatusProcessed  = "processed"
+	SourceStatusFailed     = "failed"
+
+	JobIngestDocument = "ingest_document"
+)
+
+type ingestDocumentPayload struct {
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+// DocumentProcessor ingests uploaded documents through the job queue, so an
+// upload survives restarts and failed attempts are retried with backoff.
+type DocumentProcessor struct {
+	config      util.Config
+	store       *db.SQLStore
+	files       storage.Storage
+	vectorStore VectorStore
+	embedder    EmbeddingProvider
+	queue       *jobs.Queue
+}
+
+func NewDocumentProcessor(config util.Config, store *db.SQLStore, files storage.Storage, vectorStore VectorStore, embedder EmbeddingProvider, queue *jobs.Queue) *DocumentProcessor {
+	return &DocumentProcessor{
+		config:      config,
+		store:       store,
+		files:       files,
+		vectorStore: vectorStore,
+		embedder:    embedder,
+		queue:       queue,
+	}
+}
+
+func (p *DocumentProcessor) Enqueue(ctx context.Context, sourceID uuid.UUID) error {
+	_, err := p.queue.Enqueue(ctx, JobIngestDocument, ingestDocumentPayload{SourceID: sourceID})
+	return err
+}
+
+func (p *DocumentProcessor) Register(w *jobs.Worker) {
+	w.Register(JobIngestDocument, p.handle)
+}
+
+func (p *DocumentProcessor) handle(ctx context.Context, job db.Job) error {
+	var payload ingestDocumentPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+	return p.process(ctx, payload.SourceID)
+}
+
+func (p *DocumentProcessor) process(ctx context.Context, sourceID uuid.UUID) error {
+	source, err := p.store.Querier.ClaimSourceForProcessing(ctx, sourceID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already processed or deleted since it was queued.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := p.ingest(ctx, source)
+	if err != nil {
+		markErr := p.store.Querier.MarkSourceFailed(ctx, db.MarkSourceFailedParams{
+			SourceExternalID: sourceID,
+			ErrorMessage:     pgtype.Text{String: err.Error(), Valid: true},
+		})
+		if markErr != nil {
+			return fmt.Errorf("%w (and cannot mark failed: %v)", err, markErr)
+		}
+		// The job is retried; each attempt claims the source again from failed.
+		return err
+	}
+
+	fmt.Printf("Document %s processed: %s\n", source.Filename.String, report)
+	return p.store.Querier.MarkSourceProcessed(ctx, sourceID)
+}
+
+func (p *DocumentProcessor) ingest(ctx context.Context, source db.SourceFile) (IngestReport, error) {
+	// Loaders read from a path, so copy the object to a local temp file that
+	// keeps the original extension.
+	r, err := p.files.Open(ctx, source.StorageKey)
+	if err != nil {
+		return IngestReport{}, fmt.Errorf("cannot open stored file: %w", err)
+	}
+	defer r.Close()
+
+	tmp, err := os.CreateTemp("", "document-*"+filepath.Ext(source.Filename.String))
+	if err != nil {
+		return IngestReport{}, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return IngestReport{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return IngestReport{}, err
+	}
+
+	opts := IngestOptions{
+		SourceID:   source.SourceExternalID,
+		SourcePath: source.StorageKey,
+		Filename:   source.Filename.String,
+		Mime:       source.MimeType.String,
+		Department: source.Department.String,
+	}
+	if source.UploadedBy.Valid {
+		opts.CreatedBy = source.UploadedBy.Bytes
+	}
+	return CreateVectorStore(ctx, p.config, p.embedder, p.vectorStore, tmp.Name(), opts)
+}
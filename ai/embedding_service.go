@@ -0,0 +1,338 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	// embedLinger is how long a batch waits for more texts before it is
+	// sent, trading a few milliseconds of latency for fewer provider calls.
+	embedLinger      = 10 * time.Millisecond
+	embedCallTimeout = 2 * time.Minute
+	maxEmbedCalls    = 4
+	embedPrunePeriod = time.Hour
+)
+
+// EmbeddingService puts a Postgres cache and a batcher in front of an
+// EmbeddingProvider. Vectors are cached by the SHA-256 of the text and the
+// model name, so repeated questions and re-ingested documents are free.
+// Texts that miss the cache are queued, and the queue is flushed in batches
+// of up to the provider's limit, so concurrent callers share requests; a
+// text already on its way to the provider is waited for, not sent twice.
+//
+// It implements EmbeddingProvider, and Start must be running for Embed to
+// return.
+type EmbeddingService struct {
+	provider  EmbeddingProvider
+	store     *db.SQLStore
+	batchSize int
+	ttl       time.Duration
+
+	queue chan *embedCall
+	calls chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]*embedCall
+
+	requested     atomic.Int64
+	cacheHits     atomic.Int64
+	merged        atomic.Int64
+	embedded      atomic.Int64
+	providerCalls atomic.Int64
+}
+
+type embedCall struct {
+	text string
+	hash string
+	done chan struct{}
+	vec  []float64
+	err  error
+}
+
+// EmbeddingStats counts texts since the process started. Requested is split
+// into CacheHits, Merged (served by an identical text already in flight) and
+// Embedded (sent to the provider).
+type EmbeddingStats struct {
+	Model         string
+	Requested     int64
+	CacheHits     int64
+	Merged        int64
+	Embedded      int64
+	ProviderCalls int64
+	HitRate       float64
+}
+
+func NewEmbeddingService(config util.Config, store *db.SQLStore) (*EmbeddingService, error) {
+	provider, err := NewEmbeddingProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	batchSize := config.EmbeddingBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultEmbeddingBatch(config)
+	}
+	return &EmbeddingService{
+		provider:  provider,
+		store:     store,
+		batchSize: batchSize,
+		ttl:       config.EmbeddingCacheTTL,
+		queue:     make(chan *embedCall, batchSize*maxEmbedCalls),
+		calls:     make(chan struct{}, maxEmbedCalls),
+		inflight:  make(map[string]*embedCall),
+	}, nil
+}
+
+// defaultEmbeddingBatch stays well under each provider's documented input
+// limit (2048 for OpenAI), since long chunks also count against the
+// per-request token cap.
+func defaultEmbeddingBatch(config util.Config) int {
+	name := config.AIEmbeddingProvider
+	if name == "" {
+		name = config.AIProvider
+	}
+	switch providerName(name) {
+	case ProviderOpenAI:
+		return 256
+	case ProviderOllama:
+		return 32
+	default:
+		return 64
+	}
+}
+
+func (s *EmbeddingService) Model() string {
+	return s.provider.Model()
+}
+
+func (s *EmbeddingService) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	if len(texts) == 0 {
+		return out, nil
+	}
+	s.requested.Add(int64(len(texts)))
+
+	hashes := make([]string, len(texts))
+	for i, t := range texts {
+		hashes[i] = contentHash(t)
+	}
+	cached := s.lookup(ctx, hashes)
+
+	// One call per distinct text that missed the cache; duplicates within
+	// texts, and texts another caller is already embedding, share it.
+	pending := make(map[string]*embedCall)
+	for i, h := range hashes {
+		if vec, ok := cached[h]; ok {
+			out[i] = vec
+			s.cacheHits.Add(1)
+			continue
+		}
+		if _, ok := pending[h]; ok {
+			s.merged.Add(1)
+			continue
+		}
+		call, fresh, err := s.join(ctx, texts[i], h)
+		if err != nil {
+			return nil, err
+		}
+		if !fresh {
+			s.merged.Add(1)
+		}
+		pending[h] = call
+	}
+
+	for _, call := range pending {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+	}
+	for i, h := range hashes {
+		if out[i] == nil {
+			out[i] = pending[h].vec
+		}
+	}
+	return out, nil
+}
+
+// join returns the in-flight call for hash, queueing a new one if there is
+// none. fresh reports whether the call was created here. If ctx ends before
+// the batcher takes a new call, the call is failed and forgotten, so callers
+// that joined it meanwhile are released too and the next one starts over.
+func (s *EmbeddingService) join(ctx context.Context, text, hash string) (*embedCall, bool, error) {
+	s.mu.Lock()
+	if call, ok := s.inflight[hash]; ok {
+		s.mu.Unlock()
+		return call, false, nil
+	}
+	call := &embedCall{text: text, hash: hash, done: make(chan struct{})}
+	s.inflight[hash] = call
+	s.mu.Unlock()
+
+	// Once queued it is never abandoned: other callers may be waiting on it.
+	select {
+	case s.queue <- call:
+		return call, true, nil
+	case <-ctx.Done():
+	}
+	s.mu.Lock()
+	delete(s.inflight, hash)
+	call.err = ctx.Err()
+	close(call.done)
+	s.mu.Unlock()
+	return nil, false, ctx.Err()
+}
+
+// lookup returns the cached vectors for hashes. A cache that cannot be read
+// only costs money, so errors are logged and treated as misses.
+func (s *EmbeddingService) lookup(ctx context.Context, hashes []string) map[string][]float64 {
+	found := make(map[string][]float64)
+	rows, err := s.store.Querier.GetCachedEmbeddings(ctx, db.GetCachedEmbeddingsParams{
+		Model:  s.Model(),
+		Hashes: hashes,
+	})
+	if err != nil {
+		fmt.Printf("Cannot read embedding cache: %v\n", err)
+		return found
+	}
+	hit := make([]string, 0, len(rows))
+	for _, r := range rows {
+		found[r.ContentHash] = r.Embedding
+		hit = append(hit, r.ContentHash)
+	}
+	if len(hit) > 0 {
+		err = s.store.Querier.TouchCachedEmbeddings(ctx, db.TouchCachedEmbeddingsParams{
+			Model:  s.Model(),
+			Hashes: hit,
+		})
+		if err != nil {
+			fmt.Printf("Cannot update embedding cache usage: %v\n", err)
+		}
+	}
+	return found
+}
+
+// Start runs the batcher, and prunes cache entries unused for longer than
+// EMBEDDING_CACHE_TTL when one is set, until ctx is cancelled.
+func (s *EmbeddingService) Start(ctx context.Context) {
+	if s.ttl > 0 {
+		go s.prune(ctx)
+	}
+	for {
+		var first *embedCall
+		select {
+		case <-ctx.Done():
+			return
+		case first = <-s.queue:
+		}
+
+		batch := []*embedCall{first}
+		linger := time.NewTimer(embedLinger)
+	collect:
+		for len(batch) < s.batchSize {
+			select {
+			case call := <-s.queue:
+				batch = append(batch, call)
+			case <-linger.C:
+				break collect
+			}
+		}
+		linger.Stop()
+
+		s.calls <- struct{}{}
+		go func() {
+			defer func() { <-s.calls }()
+			s.flush(batch)
+		}()
+	}
+}
+
+func (s *EmbeddingService) flush(batch []*embedCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), embedCallTimeout)
+	defer cancel()
+
+	texts := make([]string, len(batch))
+	for i, call := range batch {
+		texts[i] = call.text
+	}
+	s.providerCalls.Add(1)
+	s.embedded.Add(int64(len(batch)))
+	vecs, err := s.provider.Embed(ctx, texts)
+	if err == nil && len(vecs) != len(batch) {
+		err = fmt.Errorf("embeddings count mismatch: got %d for %d texts", len(vecs), len(batch))
+	}
+
+	for i, call := range batch {
+		if err != nil {
+			call.err = err
+			continue
+		}
+		call.vec = vecs[i]
+		cacheErr := s.store.Querier.InsertCachedEmbedding(ctx, db.InsertCachedEmbeddingParams{
+			ContentHash: call.hash,
+			Model:       s.Model(),
+			Embedding:   call.vec,
+		})
+		if cacheErr != nil {
+			fmt.Printf("Cannot cache embedding: %v\n", cacheErr)
+		}
+	}
+
+	// Callers are released only once the vectors are in the cache, so a
+	// text is always either cached or in flight.
+	s.mu.Lock()
+	for _, call := range batch {
+		delete(s.inflight, call.hash)
+		close(call.done)
+	}
+	s.mu.Unlock()
+}
+
+func (s *EmbeddingService) prune(ctx context.Context) {
+	ticker := time.NewTicker(embedPrunePeriod)
+	defer ticker.Stop()
+	for {
+		cutoff := pgtype.Timestamptz{Time: time.Now().Add(-s.ttl), Valid: true}
+		if err := s.store.Querier.DeleteUnusedEmbeddings(ctx, cutoff); err != nil {
+			fmt.Printf("Cannot prune embedding cache: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *EmbeddingService) Stats() EmbeddingStats {
+	stats := EmbeddingStats{
+		Model:         s.Model(),
+		Requested:     s.requested.Load(),
+		CacheHits:     s.cacheHits.Load(),
+		Merged:        s.merged.Load(),
+		Embedded:      s.embedded.Load(),
+		ProviderCalls: s.providerCalls.Load(),
+	}
+	if stats.Requested > 0 {
+		stats.HitRate = float64(stats.CacheHits) / float64(stats.Requested)
+	}
+	return stats
+}
+
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+// emptyCache is a store whose embedding cache never has a hit.
+type emptyCache struct {
+	db.Querier
+}
+
+func (emptyCache) GetCachedEmbeddings(context.Context, db.GetCachedEmbeddingsParams) ([]db.GetCachedEmbeddingsRow, error) {
+	return nil, nil
+}
+
+func TestEmbeddingServiceEmbedGivesUpOnStuckQueue(t *testing.T) {
+	// No batcher runs, so nothing ever takes the call off the queue.
+	s := &EmbeddingService{
+		provider:  NewFakeProvider(),
+		store:     &db.SQLStore{Querier: emptyCache{}},
+		batchSize: 1,
+		queue:     make(chan *embedCall),
+		calls:     make(chan struct{}, maxEmbedCalls),
+		inflight:  make(map[string]*embedCall),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Embed(ctx, []string{"refund policy"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Embed() error = %v, want %v", err, context.Canceled)
+	}
+	if n := len(s.inflight); n != 0 {
+		t.Errorf("%d calls left in flight, want none", n)
+	}
+}
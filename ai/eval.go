@@ -0,0 +1,276 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+	"go.yaml.in/yaml/v3"
+)
+
+// EvalCase is one question of a golden set. ExpectedChunks are chunk IDs and
+// ExpectedSources are filenames, optionally with a page as "guide.pdf#3";
+// sources survive re-ingestion, chunk IDs do not. Each keyword must appear
+// in the answer, and "refund|بازپرداخت" accepts either spelling.
+type EvalCase struct {
+	ID              string   `json:"id" yaml:"id"`
+	Question        string   `json:"question" yaml:"question"`
+	Language        string   `json:"language,omitempty" yaml:"language"`
+	Department      string   `json:"department,omitempty" yaml:"department"`
+	ExpectedChunks  []string `json:"expected_chunks,omitempty" yaml:"expected_chunks"`
+	ExpectedSources []string `json:"expected_sources,omitempty" yaml:"expected_sources"`
+	Keywords        []string `json:"keywords,omitempty" yaml:"keywords"`
+}
+
+// LoadEvalSet reads a golden set from a .jsonl file, one case per line, or
+// from a .yaml, .yml or .json file holding a list of cases.
+func LoadEvalSet(path string) ([]EvalCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []EvalCase
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl":
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var c EvalCase
+			if err := json.Unmarshal([]byte(text), &c); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&cases); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.NewDecoder(f).Decode(&cases); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported golden set format %q, use .jsonl, .json or .yaml", filepath.Ext(path))
+	}
+
+	seen := make(map[string]bool)
+	for i := range cases {
+		if strings.TrimSpace(cases[i].Question) == "" {
+			return nil, fmt.Errorf("case %d in %s has no question", i+1, path)
+		}
+		if cases[i].ID == "" {
+			cases[i].ID = fmt.Sprintf("case-%d", i+1)
+		}
+		if seen[cases[i].ID] {
+			return nil, fmt.Errorf("duplicate case id %q in %s", cases[i].ID, path)
+		}
+		seen[cases[i].ID] = true
+	}
+	return cases, nil
+}
+
+// Evaluator runs golden questions through the bot's retrieval, packing and
+// completion, without a chat or the database behind it, and scores what
+// comes out. Any providers and vector store can be plugged in, so a run with
+// the fake provider over a few files works offline.
+type Evaluator struct {
+	config    util.Config
+	retriever *Retriever
+	completer CompletionProvider
+	embedder  EmbeddingProvider
+	languages languagePolicy
+	contexts  *ContextBuilder
+}
+
+// NewEvaluator returns an evaluator; a nil completer scores retrieval only.
+func NewEvaluator(config util.Config, vectorStore VectorStore, embedder EmbeddingProvider, completer CompletionProvider) *Evaluator {
+	model := ""
+	if completer != nil {
+		model = completer.Model()
+	}
+	return &Evaluator{
+		config:    config,
+		retriever: NewRetriever(config, embedder, vectorStore),
+		completer: completer,
+		embedder:  embedder,
+		languages: newLanguagePolicy(config),
+		contexts:  NewContextBuilder(config, model),
+	}
+}
+
+// EvalOptions names the run in its report and picks k; TopK 0 uses
+// RETRIEVAL_TOP_K like the bot.
+type EvalOptions struct {
+	Name     string
+	TopK     int
+	Template PromptTemplate
+}
+
+// Run evaluates every case in turn. A case that fails is recorded with its
+// error and the run goes on.
+func (e *Evaluator) Run(ctx context.Context, cases []EvalCase, opts EvalOptions) EvalReport {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = e.contexts.TopK()
+	}
+	report := EvalReport{
+		Name:           opts.Name,
+		CreatedAt:      time.Now().UTC(),
+		EmbeddingModel: e.embedder.Model(),
+		TopK:           topK,
+		Template:       fmt.Sprintf("%s v%d", opts.Template.Name, opts.Template.Version),
+		ChunkSize:      e.config.ChunkSize,
+		ChunkOverlap:   e.config.ChunkOverlap,
+	}
+	if opts.Template.ID == uuid.Nil {
+		report.Template = opts.Template.Name + " (built-in)"
+	}
+	if e.completer != nil {
+		report.CompletionModel = e.completer.Model()
+	}
+
+	for _, c := range cases {
+		if ctx.Err() != nil {
+			break
+		}
+		result := e.runCase(ctx, c, topK, opts.Template)
+		if result.Error != "" {
+			fmt.Printf("Case %s failed: %s\n", c.ID, result.Error)
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.Summary = summarize(report.Results)
+	return report
+}
+
+func (e *Evaluator) runCase(ctx context.Context, c EvalCase, topK int, t PromptTemplate) (result EvalResult) {
+	result = EvalResult{ID: c.ID, Question: c.Question}
+	start := time.Now()
+	defer func() { result.LatencyMs = time.Since(start).Milliseconds() }()
+
+	lang := c.Language
+	if lang == "" {
+		lang = e.languages.replyLanguage(c.Question, nil)
+	}
+	found, err := retrievePreferringLanguage(ctx, e.retriever, c.Question, topK, SearchFilter{
+		Department: c.Department,
+		Language:   lang,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("retrieval failed: %v", err)
+		return result
+	}
+	for _, chunk := range found.Chunks {
+		result.Retrieved = append(result.Retrieved, EvalChunk{ID: chunk.ID.String(), Source: chunk.sourceLabel()})
+	}
+	scoreRetrieval(&result, c, found.Chunks)
+
+	if e.completer == nil {
+		return result
+	}
+	vars := promptVars(e.config.CompanyName, c.Question, nil, nil, lang)
+	packed, err := e.contexts.Pack(t, vars, found.Chunks, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("cannot render prompt: %v", err)
+		return result
+	}
+	result.PromptTokens = packed.Tokens
+	resp, err := e.completer.Complete(ctx, CompletionRequest{
+		Messages:    packed.Messages,
+		Temperature: e.config.AITemperature,
+		MaxTokens:   e.contexts.AnswerTokens(),
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("completion failed: %v", err)
+		return result
+	}
+	result.Answer = resp.Content
+	scoreAnswer(&result, c.Keywords)
+	return result
+}
+
+// scoreRetrieval computes recall@k over the expectations of c, and the
+// reciprocal rank of the first chunk meeting any of them.
+func scoreRetrieval(result *EvalResult, c EvalCase, chunks []Chunk) {
+	expected := len(c.ExpectedChunks) + len(c.ExpectedSources)
+	if expected == 0 {
+		return
+	}
+	result.HasExpectations = true
+
+	firstHit := 0
+	check := func(want string, match func(Chunk) bool) {
+		for rank, chunk := range chunks {
+			if match(chunk) {
+				result.Found = append(result.Found, want)
+				if firstHit == 0 || rank+1 < firstHit {
+					firstHit = rank + 1
+				}
+				return
+			}
+		}
+		result.Missing = append(result.Missing, want)
+	}
+	for _, id := range c.ExpectedChunks {
+		id = strings.TrimSpace(id)
+		check(id, func(chunk Chunk) bool { return strings.EqualFold(chunk.ID.String(), id) })
+	}
+	for _, source := range c.ExpectedSources {
+		source = strings.TrimSpace(source)
+		check(source, func(chunk Chunk) bool { return sourceMatches(chunk, source) })
+	}
+
+	result.Recall = float64(len(result.Found)) / float64(expected)
+	if firstHit > 0 {
+		result.ReciprocalRank = 1 / float64(firstHit)
+	}
+}
+
+func sourceMatches(c Chunk, source string) bool {
+	name, page, hasPage := strings.Cut(source, "#")
+	if !strings.EqualFold(c.SourceFilename, name) && !strings.EqualFold(c.SourcePath, name) {
+		return false
+	}
+	return !hasPage || page == fmt.Sprint(c.SourcePage)
+}
+
+// scoreAnswer checks the keywords after normalizeForMatch, so case,
+// punctuation and Arabic or Persian letter forms do not matter.
+func scoreAnswer(result *EvalResult, keywords []string) {
+	if len(keywords) == 0 {
+		return
+	}
+	result.HasKeywords = true
+	answer := " " + normalizeForMatch(result.Answer) + " "
+	for _, keyword := range keywords {
+		matched := false
+		for _, alt := range strings.Split(keyword, "|") {
+			if alt = normalizeForMatch(alt); alt != "" && strings.Contains(answer, " "+alt+" ") {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			result.MatchedKeywords = append(result.MatchedKeywords, keyword)
+		} else {
+			result.MissingKeywords = append(result.MissingKeywords, keyword)
+		}
+	}
+	result.KeywordScore = float64(len(result.MatchedKeywords)) / float64(len(keywords))
+}
@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// EvalReport is the outcome of one run, with the settings that are usually
+// varied between runs so two reports can be told apart.
+type EvalReport struct {
+	Name            string       `json:"name"`
+	CreatedAt       time.Time    `json:"created_at"`
+	CompletionModel string       `json:"completion_model,omitempty"`
+	EmbeddingModel  string       `json:"embedding_model"`
+	Template        string       `json:"template"`
+	TopK            int          `json:"top_k"`
+	ChunkSize       int64        `json:"chunk_size"`
+	ChunkOverlap    int64        `json:"chunk_overlap"`
+	Summary         EvalSummary  `json:"summary"`
+	Results         []EvalResult `json:"results"`
+}
+
+type EvalChunk struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+}
+
+type EvalResult struct {
+	ID              string      `json:"id"`
+	Question        string      `json:"question"`
+	Retrieved       []EvalChunk `json:"retrieved"`
+	HasExpectations bool        `json:"has_expectations"`
+	Found           []string    `json:"found,omitempty"`
+	Missing         []string    `json:"missing,omitempty"`
+	Recall          float64     `json:"recall"`
+	ReciprocalRank  float64     `json:"reciprocal_rank"`
+	Answer          string      `json:"answer,omitempty"`
+	HasKeywords     bool        `json:"has_keywords"`
+	MatchedKeywords []string    `json:"matched_keywords,omitempty"`
+	MissingKeywords []string    `json:"missing_keywords,omitempty"`
+	KeywordScore    float64     `json:"keyword_score"`
+	PromptTokens    int         `json:"prompt_tokens,omitempty"`
+	LatencyMs       int64       `json:"latency_ms"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// EvalSummary averages over the cases that have something to score:
+// retrieval metrics over cases with expectations, answer metrics over cases
+// with keywords. AnswerPassRate is the share of those answers that contain
+// every keyword.
+type EvalSummary struct {
+	Cases          int     `json:"cases"`
+	Errors         int     `json:"errors"`
+	RecallAtK      float64 `json:"recall_at_k"`
+	HitRate        float64 `json:"hit_rate"`
+	MRR            float64 `json:"mrr"`
+	KeywordScore   float64 `json:"keyword_score"`
+	AnswerPassRate float64 `json:"answer_pass_rate"`
+	MeanLatencyMs  int64   `json:"mean_latency_ms"`
+}
+
+func summarize(results []EvalResult) EvalSummary {
+	s := EvalSummary{Cases: len(results)}
+	var retrieval, answers int
+	var latency int64
+	for _, r := range results {
+		latency += r.LatencyMs
+		if r.Error != "" {
+			s.Errors++
+		}
+		if r.HasExpectations {
+			retrieval++
+			s.RecallAtK += r.Recall
+			s.MRR += r.ReciprocalRank
+			if len(r.Found) > 0 {
+				s.HitRate++
+			}
+		}
+		if r.HasKeywords {
+			answers++
+			s.KeywordScore += r.KeywordScore
+			if len(r.MissingKeywords) == 0 {
+				s.AnswerPassRate++
+			}
+		}
+	}
+	if retrieval > 0 {
+		s.RecallAtK /= float64(retrieval)
+		s.MRR /= float64(retrieval)
+		s.HitRate /= float64(retrieval)
+	}
+	if answers > 0 {
+		s.KeywordScore /= float64(answers)
+		s.AnswerPassRate /= float64(answers)
+	}
+	if len(results) > 0 {
+		s.MeanLatencyMs = latency / int64(len(results))
+	}
+	return s
+}
+
+func WriteEvalReport(path string, report EvalReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func LoadEvalReport(path string) (EvalReport, error) {
+	var report EvalReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+// PrintEvalSummary writes the settings and metrics of report, and the cases
+// that missed something.
+func PrintEvalSummary(w io.Writer, report EvalReport) {
+	fmt.Fprintf(w, "Run %q: %s\n", report.Name, reportSettings(report))
+	s := report.Summary
+	fmt.Fprintf(w, "  cases %d, errors %d, mean latency %dms\n", s.Cases, s.Errors, s.MeanLatencyMs)
+	fmt.Fprintf(w, "  recall@%d %.3f, hit rate %.3f, MRR %.3f\n", report.TopK, s.RecallAtK, s.HitRate, s.MRR)
+	if report.CompletionModel != "" {
+		fmt.Fprintf(w, "  keyword score %.3f, answers passing %.3f\n", s.KeywordScore, s.AnswerPassRate)
+	}
+
+	for _, r := range report.Results {
+		var problems []string
+		if r.Error != "" {
+			problems = append(problems, r.Error)
+		}
+		if len(r.Missing) > 0 {
+			problems = append(problems, "not retrieved: "+strings.Join(r.Missing, ", "))
+		}
+		if len(r.MissingKeywords) > 0 {
+			problems = append(problems, "missing keywords: "+strings.Join(r.MissingKeywords, ", "))
+		}
+		if len(problems) > 0 {
+			fmt.Fprintf(w, "  - %s: %s\n", r.ID, strings.Join(problems, "; "))
+		}
+	}
+}
+
+// PrintEvalComparison writes how current did against baseline: the change
+// in every metric, then each case whose recall or keyword score moved.
+// Cases are matched by ID; ones only in one of the reports are listed too.
+func PrintEvalComparison(w io.Writer, baseline, current EvalReport) {
+	fmt.Fprintf(w, "Comparing %q against baseline %q\n", current.Name, baseline.Name)
+	if b, c := reportSettings(baseline), reportSettings(current); b != c {
+		fmt.Fprintf(w, "  baseline: %s\n  current:  %s\n", b, c)
+	}
+	bs, cs := baseline.Summary, current.Summary
+	metrics := []struct {
+		name     string
+		old, new float64
+	}{
+		{"recall@k", bs.RecallAtK, cs.RecallAtK},
+		{"hit rate", bs.HitRate, cs.HitRate},
+		{"MRR", bs.MRR, cs.MRR},
+		{"keyword score", bs.KeywordScore, cs.KeywordScore},
+		{"answers passing", bs.AnswerPassRate, cs.AnswerPassRate},
+		{"mean latency ms", float64(bs.MeanLatencyMs), float64(cs.MeanLatencyMs)},
+		{"errors", float64(bs.Errors), float64(cs.Errors)},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "  %-16s %8.3f -> %8.3f  (%+.3f)\n", m.name, m.old, m.new, m.new-m.old)
+	}
+
+	before := make(map[string]EvalResult, len(baseline.Results))
+	for _, r := range baseline.Results {
+		before[r.ID] = r
+	}
+	var better, worse int
+	for _, r := range current.Results {
+		old, ok := before[r.ID]
+		if !ok {
+			fmt.Fprintf(w, "  new   %s\n", r.ID)
+			continue
+		}
+		delete(before, r.ID)
+		delta := (r.Recall - old.Recall) + (r.KeywordScore - old.KeywordScore)
+		switch {
+		case delta > 0:
+			better++
+		case delta < 0:
+			worse++
+		default:
+			continue
+		}
+		fmt.Fprintf(w, "  %-5s %s: recall %.2f -> %.2f, keywords %.2f -> %.2f\n",
+			caseTrend(delta), r.ID, old.Recall, r.Recall, old.KeywordScore, r.KeywordScore)
+	}
+	for _, r := range baseline.Results {
+		if _, ok := before[r.ID]; ok {
+			fmt.Fprintf(w, "  gone  %s\n", r.ID)
+		}
+	}
+	fmt.Fprintf(w, "  %d cases better, %d worse\n", better, worse)
+}
+
+func caseTrend(delta float64) string {
+	if delta > 0 {
+		return "up"
+	}
+	return "down"
+}
+
+func reportSettings(r EvalReport) string {
+	model := r.CompletionModel
+	if model == "" {
+		model = "retrieval only"
+	}
+	return fmt.Sprintf("model %s, embeddings %s, template %s, top %d, chunk size %d, overlap %d",
+		model, r.EmbeddingModel, r.Template, r.TopK, r.ChunkSize, r.ChunkOverlap)
+}
@@ -0,0 +1,179 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+func TestEvaluatorRunFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	config := util.Config{AIProvider: ProviderFake}
+	embedder, err := NewEmbeddingProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	completer, err := NewCompletionProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []Chunk{
+		{SourceFilename: "refunds.md", SourcePage: 1, Text: "Refunds are paid back to the original card within 14 days of the return."},
+		{SourceFilename: "shipping.md", SourcePage: 2, Text: "Orders ship from the Tehran warehouse and arrive within three working days."},
+	}
+	texts := []string{docs[0].Text, docs[1].Text}
+	embeddings, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range docs {
+		docs[i].Embedding = embeddings[i]
+	}
+	store := NewMemoryVectorStore()
+	if err := store.Add(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []EvalCase{
+		{
+			ID:              "refund",
+			Question:        "How many days until refunds reach my card?",
+			ExpectedSources: []string{"refunds.md#1"},
+			Keywords:        []string{"14 days", "card|کارت"},
+		},
+		{
+			ID:              "shipping",
+			Question:        "Which warehouse do orders ship from?",
+			ExpectedSources: []string{"shipping.md", "returns.pdf"},
+			Keywords:        []string{"Tehran", "courier"},
+		},
+	}
+	report := NewEvaluator(config, store, embedder, completer).Run(ctx, cases, EvalOptions{
+		Name:     "test",
+		TopK:     1,
+		Template: BuiltinPromptTemplate(),
+	})
+
+	if len(report.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(report.Results))
+	}
+	refund, shipping := report.Results[0], report.Results[1]
+	for _, r := range report.Results {
+		if r.Error != "" {
+			t.Fatalf("case %s failed: %s", r.ID, r.Error)
+		}
+		if len(r.Retrieved) != 1 {
+			t.Errorf("case %s retrieved %d chunks, want 1", r.ID, len(r.Retrieved))
+		}
+	}
+	if refund.Recall != 1 || refund.ReciprocalRank != 1 {
+		t.Errorf("refund recall %v, reciprocal rank %v, want 1 and 1", refund.Recall, refund.ReciprocalRank)
+	}
+	if refund.KeywordScore != 1 {
+		t.Errorf("refund keyword score %v, missing %v", refund.KeywordScore, refund.MissingKeywords)
+	}
+	if shipping.Recall != 0.5 || !reflect.DeepEqual(shipping.Missing, []string{"returns.pdf"}) {
+		t.Errorf("shipping recall %v, missing %v, want 0.5 and [returns.pdf]", shipping.Recall, shipping.Missing)
+	}
+	if shipping.KeywordScore != 0.5 || !reflect.DeepEqual(shipping.MissingKeywords, []string{"courier"}) {
+		t.Errorf("shipping keyword score %v, missing %v, want 0.5 and [courier]", shipping.KeywordScore, shipping.MissingKeywords)
+	}
+
+	s := report.Summary
+	want := EvalSummary{Cases: 2, RecallAtK: 0.75, HitRate: 1, MRR: 1, KeywordScore: 0.75, AnswerPassRate: 0.5}
+	if s.Cases != want.Cases || s.Errors != want.Errors ||
+		!approxEqual(s.RecallAtK, want.RecallAtK) || !approxEqual(s.HitRate, want.HitRate) ||
+		!approxEqual(s.MRR, want.MRR) || !approxEqual(s.KeywordScore, want.KeywordScore) ||
+		!approxEqual(s.AnswerPassRate, want.AnswerPassRate) {
+		t.Errorf("summary %+v, want %+v", s, want)
+	}
+}
+
+func TestLoadEvalSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []EvalCase
+		wantErr bool
+	}{
+		{
+			name: "jsonl",
+			file: "golden.jsonl",
+			content: `{"id":"reset","question":"How do I reset my password?","expected_sources":["guide.pdf#3"],"keywords":["reset"]}
+
+{"question":"بازپرداخت چقدر طول می‌کشد؟","language":"fa","keywords":["refund|بازپرداخت"]}
+`,
+			want: []EvalCase{
+				{ID: "reset", Question: "How do I reset my password?", ExpectedSources: []string{"guide.pdf#3"}, Keywords: []string{"reset"}},
+				{ID: "case-2", Question: "بازپرداخت چقدر طول می‌کشد؟", Language: "fa", Keywords: []string{"refund|بازپرداخت"}},
+			},
+		},
+		{
+			name: "yaml",
+			file: "golden.yaml",
+			content: `- id: reset
+  question: How do I reset my password?
+  department: accounts
+  expected_chunks: [0b0d0f6e-2c55-4a4e-9f7e-1b2f4e1f2a3b]
+- question: Where is my order?
+  keywords:
+    - tracking
+`,
+			want: []EvalCase{
+				{ID: "reset", Question: "How do I reset my password?", Department: "accounts", ExpectedChunks: []string{"0b0d0f6e-2c55-4a4e-9f7e-1b2f4e1f2a3b"}},
+				{ID: "case-2", Question: "Where is my order?", Keywords: []string{"tracking"}},
+			},
+		},
+		{
+			name:    "duplicate id",
+			file:    "golden.jsonl",
+			content: "{\"id\":\"a\",\"question\":\"one\"}\n{\"id\":\"a\",\"question\":\"two\"}\n",
+			wantErr: true,
+		},
+		{
+			name:    "no question",
+			file:    "golden.yml",
+			content: "- id: a\n  question: \"  \"\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad line",
+			file:    "golden.jsonl",
+			content: "{\"question\":\"one\"}\nnot json\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			file:    "golden.csv",
+			content: "question\none\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadEvalSet(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadEvalSet() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadEvalSet() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
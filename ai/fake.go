@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// FakeProvider is a deterministic, offline backend. Embeddings are hashed
+// bag-of-words vectors, so texts sharing words end up close together, and
+// completions echo the last user message back. Dimensions defaults to the
+// vector store's, so the fake can fill a pgvector table too.
+type FakeProvider struct {
+	Dimensions int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{Dimensions: defaultEmbeddingDimensions}
+}
+
+func (p *FakeProvider) Model() string {
+	return ProviderFake
+}
+
+func (p *FakeProvider) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return CompletionResponse{}, err
+	}
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			return CompletionResponse{Content: req.Messages[i].Content}, nil
+		}
+	}
+	return CompletionResponse{}, nil
+}
+
+func (p *FakeProvider) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (CompletionResponse, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+	words := strings.SplitAfter(resp.Content, " ")
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if err := onDelta(w); err != nil {
+			return CompletionResponse{}, err
+		}
+	}
+	return resp, nil
+}
+
+func (p *FakeProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out := make([][]float64, len(texts))
+	for i, t := range texts {
+		out[i] = p.embed(t)
+	}
+	return out, nil
+}
+
+func (p *FakeProvider) embed(text string) []float64 {
+	dims := p.Dimensions
+	if dims <= 0 {
+		dims = defaultEmbeddingDimensions
+	}
+	vec := make([]float64, dims)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		h := fnv.New64a()
+		h.Write([]byte(w))
+		sum := h.Sum64()
+		sign := 1.0
+		if sum&1 == 1 {
+			sign = -1.0
+		}
+		vec[(sum>>1)%uint64(dims)] += sign
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm == 0 {
+		return vec
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
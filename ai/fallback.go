@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+// fallbackNotices stand in for an answer the AI backend could not give. They
+// point at the handoff phrases, which work without the backend.
+var fallbackNotices = map[string]string{
+	LanguagePersian: "متأسفانه در حال حاضر امکان پاسخ\u200cگویی ندارم. لطفاً چند دقیقه دیگر دوباره بپرسید یا بنویسید «با اپراتور» تا یکی از همکاران پشتیبانی پاسخ دهد.",
+	LanguageArabic:  "عذراً، لا أستطيع الإجابة في الوقت الحالي. يرجى المحاولة مرة أخرى بعد بضع دقائق، أو اطلب التحدث مع موظف ليرد عليك أحد أعضاء فريق الدعم.",
+	LanguageEnglish: "Sorry, I can't answer right now. Please try again in a few minutes, or ask to talk to a person and a member of our support team will reply.",
+}
+
+// postFallback tells the user the bot could not answer, so a backend outage
+// does not look like being ignored. Nothing is posted while the bot itself is
+// shutting down.
+func (b *Bot) postFallback(ctx context.Context, chatID, botID uuid.UUID, lang string) {
+	if ctx.Err() != nil {
+		return
+	}
+	notice, ok := fallbackNotices[lang]
+	if !ok {
+		notice = fallbackNotices[LanguageEnglish]
+	}
+	msg, err := b.store.Querier.CreateMessage(ctx, db.CreateMessageParams{
+		ChatExternalID:   chatID,
+		SenderExternalID: botID,
+		Content:          notice,
+		IsSystemMessage:  true,
+		IsAdminMessage:   false,
+	})
+	if err != nil {
+		fmt.Printf("Cannot post fallback message in %s: %v\n", chatID, err)
+		return
+	}
+	b.broadcastSystemMessage(chatID, msg)
+}
+
+func (b *Bot) broadcastSystemMessage(chatID uuid.UUID, msg db.CreateMessageRow) {
+	data, err := json.Marshal(ws.OutgoingMessage{
+		MessageExternalID: msg.MessageExternalID,
+		Content:           msg.Content,
+		SenderExternalID:  msg.SenderExternalID,
+		CreatedAt:         msg.CreatedAt.Time.Format(time.RFC3339),
+		IsSystem:          true,
+	})
+	if err != nil {
+		return
+	}
+	b.hub.Broadcast <- ws.BroadcastMessage{
+		ChatExternalID: chatID,
+		Data:           data,
+	}
+}
@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	HandoffReasonLowConfidence = "low_confidence"
+	HandoffReasonUserRequest   = "user_request"
+	HandoffReasonBotEscalation = "bot_escalation"
+
+	maxHandoffDetail = 200
+)
+
+// defaultHandoffPhrases are matched as whole words after normalizeForMatch,
+// so "Can I talk to a human?" and "با اپراتور صحبت کنم" both hit. Each one
+// asks for a person; a bare word like "operator" would also match "my mobile
+// operator blocks SMS codes" and silence the bot. HANDOFF_PHRASES replaces
+// the list.
+var defaultHandoffPhrases = []string{
+	"talk to a human", "speak to a human", "talk to a person", "speak to a person",
+	"talk to someone", "speak to someone", "talk to an operator", "speak to an operator",
+	"talk to an agent", "speak to an agent", "real person", "human agent", "live agent",
+	"با اپراتور صحبت", "با اپراتور حرف", "اپراتور انسانی", "با کارشناس صحبت", "با کارشناس حرف",
+	"با پشتیبان صحبت", "با یک انسان", "با یک آدم", "آدم واقعی",
+	"التحدث مع موظف", "التحدث مع شخص", "التحدث مع خدمة العملاء", "شخص حقيقي", "مع إنسان",
+}
+
+var handoffNotices = map[string]string{
+	LanguagePersian: "این گفتگو به یکی از همکاران پشتیبانی سپرده شد. لطفاً کمی صبر کنید، به\u200cزودی پاسخ می\u200cدهند.",
+	LanguageArabic:  "تمت إحالة هذه المحادثة إلى أحد أعضاء فريق الدعم. يرجى الانتظار قليلاً، وسيرد عليك قريباً.",
+	LanguageEnglish: "I've passed this conversation to a member of our support team. Please hold on, someone will reply here shortly.",
+}
+
+// handoffPolicy decides when the bot should stop answering and let a person
+// take over: when the user asks for one, or when the best chunk retrieval
+// found is too far from the question to answer from.
+type handoffPolicy struct {
+	minSimilarity float64
+	phrases       []string
+}
+
+func newHandoffPolicy(config util.Config) handoffPolicy {
+	p := handoffPolicy{
+		minSimilarity: config.HandoffMinSimilarity,
+		phrases:       normalizePhrases(config.HandoffPhrases),
+	}
+	if len(p.phrases) == 0 {
+		p.phrases = normalizePhrases(defaultHandoffPhrases)
+	}
+	return p
+}
+
+func normalizePhrases(phrases []string) []string {
+	var out []string
+	for _, phrase := range phrases {
+		if phrase = normalizeForMatch(phrase); phrase != "" {
+			out = append(out, phrase)
+		}
+	}
+	return out
+}
+
+func (p handoffPolicy) wantsHuman(text string) bool {
+	text = " " + normalizeForMatch(text) + " "
+	for _, phrase := range p.phrases {
+		if strings.Contains(text, " "+phrase+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// lowConfidence reports whether found is too weak to answer from, and why.
+// A threshold of 0 turns the check off, and so does a failed vector search:
+// without a similarity there is nothing to judge by.
+func (p handoffPolicy) lowConfidence(found Retrieval) (string, bool) {
+	if p.minSimilarity <= 0 || !found.HasSimilarity {
+		return "", false
+	}
+	if len(found.Chunks) == 0 {
+		return "no matching documents", true
+	}
+	if found.TopSimilarity < p.minSimilarity {
+		return fmt.Sprintf("best match similarity %.2f is below %.2f", found.TopSimilarity, p.minSimilarity), true
+	}
+	return "", false
+}
+
+// botMayReply is false once a person owns the chat: it waits for one, an
+// admin picked it up, or it is closed.
+func botMayReply(chat db.Chat) bool {
+	switch db.ChatStatusType(chat.Status) {
+	case db.ChatStatusTypeWaiting, db.ChatStatusTypeClosed:
+		return false
+	}
+	return !chat.AdminExternalID.Valid
+}
+
+type handoff struct {
+	reason     string
+	detail     string
+	similarity pgtype.Float8
+}
+
+// handOff tells the user a person will take over, parks the chat as waiting
+// and puts it on the admin feed together with the reason.
+func (b *Bot) handOff(ctx context.Context, chat db.Chat, botID uuid.UUID, lang string, h handoff) error {
+	notice, ok := handoffNotices[lang]
+	if !ok {
+		notice = handoffNotices[LanguageEnglish]
+	}
+	detail := []rune(h.detail)
+	if len(detail) > maxHandoffDetail {
+		detail = append(detail[:maxHandoffDetail], '…')
+	}
+
+	result, err := b.store.HandoffChatTx(ctx, db.HandoffChatTxParams{
+		CreateMessageParams: db.CreateMessageParams{
+			ChatExternalID:   chat.ChatExternalID,
+			SenderExternalID: botID,
+			Content:          notice,
+			IsSystemMessage:  true,
+			IsAdminMessage:   false,
+		},
+		Reason:     h.reason,
+		Detail:     pgtype.Text{String: string(detail), Valid: len(detail) > 0},
+		Similarity: h.similarity,
+	})
+	if err != nil {
+		return err
+	}
+
+	b.broadcastSystemMessage(chat.ChatExternalID, result.Message)
+
+	item := ws.NewAdminChatItem(result.Chat)
+	item.HandoffReason = result.Handoff.Reason
+	item.HandoffDetail = result.Handoff.Detail.String
+	b.hub.NotifyAdmins(item)
+	return nil
+}
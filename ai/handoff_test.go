@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+func TestHandoffPolicyWantsHuman(t *testing.T) {
+	defaults := newHandoffPolicy(util.Config{})
+	custom := newHandoffPolicy(util.Config{HandoffPhrases: []string{"Call me!", "  "}})
+	tests := []struct {
+		name   string
+		policy handoffPolicy
+		text   string
+		want   bool
+	}{
+		{"English request", defaults, "Can I talk to a human please?", true},
+		{"case and punctuation", defaults, "SPEAK TO AN AGENT!!!", true},
+		{"Persian request", defaults, "لطفا می‌خواهم با اپراتور صحبت کنم", true},
+		{"Persian with Arabic letter forms", defaults, "با كارشناس صحبت كنم", true},
+		{"Arabic request", defaults, "أريد التحدث مع موظف", true},
+		{"operator named in passing", defaults, "The operator of my network changed my plan", false},
+		{"agent named in passing", defaults, "My user agent string looks wrong", false},
+		{"human in another sense", defaults, "Where do I send human resources documents?", false},
+		{"phrase inside a longer word", defaults, "I want to talk to someoneelse's account", false},
+		{"plain question", defaults, "How do I reset my password?", false},
+		{"custom phrases replace the defaults", custom, "please call me", true},
+		{"defaults are off with custom phrases", custom, "talk to a human", false},
+		{"blank custom phrases are ignored", custom, "   ", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.wantsHuman(tt.text); got != tt.want {
+				t.Errorf("wantsHuman(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandoffPolicyLowConfidence(t *testing.T) {
+	chunks := []Chunk{{ID: uuid.New()}}
+	tests := []struct {
+		name          string
+		minSimilarity float64
+		found         Retrieval
+		want          bool
+		wantReason    string
+	}{
+		{"check off", 0, Retrieval{HasSimilarity: true}, false, ""},
+		{"vector search did not run", 0.5, Retrieval{Chunks: chunks}, false, ""},
+		{"nothing found", 0.5, Retrieval{HasSimilarity: true}, true, "no matching documents"},
+		{"best match too far", 0.5, Retrieval{Chunks: chunks, TopSimilarity: 0.31, HasSimilarity: true}, true, "best match similarity 0.31 is below 0.50"},
+		{"best match at the threshold", 0.5, Retrieval{Chunks: chunks, TopSimilarity: 0.5, HasSimilarity: true}, false, ""},
+		{"good match", 0.5, Retrieval{Chunks: chunks, TopSimilarity: 0.82, HasSimilarity: true}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newHandoffPolicy(util.Config{HandoffMinSimilarity: tt.minSimilarity})
+			reason, got := p.lowConfidence(tt.found)
+			if got != tt.want || reason != tt.wantReason {
+				t.Errorf("lowConfidence() = %q, %v, want %q, %v", reason, got, tt.wantReason, tt.want)
+			}
+		})
+	}
+}
+
+func TestBotMayReply(t *testing.T) {
+	admin := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	tests := []struct {
+		status db.ChatStatusType
+		admin  pgtype.UUID
+		want   bool
+	}{
+		{db.ChatStatusTypeOpen, pgtype.UUID{}, true},
+		{db.ChatStatusTypePending, pgtype.UUID{}, true},
+		{db.ChatStatusTypeWaiting, pgtype.UUID{}, false},
+		{db.ChatStatusTypeClosed, pgtype.UUID{}, false},
+		{db.ChatStatusTypeOpen, admin, false},
+	}
+	for _, tt := range tests {
+		chat := db.Chat{Status: string(tt.status), AdminExternalID: tt.admin}
+		if got := botMayReply(chat); got != tt.want {
+			t.Errorf("botMayReply(%s, admin %v) = %v, want %v", tt.status, tt.admin.Valid, got, tt.want)
+		}
+	}
+}
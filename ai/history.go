@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+const (
+	defaultHistoryMessages = 10
+	historyMaxChars        = 4000
+	historyMessageMaxChars = 1000
+)
+
+const rewritePrompt = `Rewrite the user's last message as a standalone search query that can be understood without the conversation.
+Resolve pronouns and references such as "the second one" using the earlier turns.
+Keep the language of the user's message. Reply with the query only.`
+
+// loadHistory returns the turns before the question, oldest first, trimmed
+// to fit historyMaxChars. Only messages stored before the question count, so
+// the question itself and anything queued behind it are left out.
+func (b *Bot) loadHistory(ctx context.Context, botID uuid.UUID, question ws.MessageEvent) ([]ChatMessage, error) {
+	limit := b.config.BotHistoryMessages
+	if limit <= 0 {
+		limit = defaultHistoryMessages
+	}
+	rows, err := b.store.Querier.ListMessagesBeforeMessage(ctx, db.ListMessagesBeforeMessageParams{
+		ChatExternalID:    question.ChatExternalID,
+		MessageExternalID: question.MessageExternalID,
+		Limit:             int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var history []ChatMessage
+	total := 0
+	// rows are newest first; walk them that way so the budget keeps the most
+	// recent turns, then prepend to restore chronological order.
+	for _, m := range rows {
+		if m.IsSystemMessage || strings.TrimSpace(m.Content) == "" {
+			continue
+		}
+		role := "user"
+		if m.SenderExternalID == botID || m.IsAdminMessage {
+			role = "assistant"
+		}
+		content := truncateRunes(m.Content, historyMessageMaxChars)
+		if total+len([]rune(content)) > historyMaxChars {
+			break
+		}
+		total += len([]rune(content))
+		history = append([]ChatMessage{{Role: role, Content: content}}, history...)
+	}
+	return history, nil
+}
+
+// rewriteQuery turns a follow-up into a question that retrieval can answer
+// on its own, with the persona's model. Any failure falls back to the
+// original text.
+func (b *Bot) rewriteQuery(ctx context.Context, p persona, history []ChatMessage, question string) string {
+	if len(history) == 0 {
+		return question
+	}
+	var convo strings.Builder
+	for _, m := range history {
+		fmt.Fprintf(&convo, "%s: %s\n", m.Role, m.Content)
+	}
+	fmt.Fprintf(&convo, "user: %s", question)
+
+	resp, err := p.model.completer.Complete(ctx, CompletionRequest{
+		Messages: []ChatMessage{
+			{Role: "system", Content: rewritePrompt},
+			{Role: "user", Content: convo.String()},
+		},
+		Temperature: 0,
+	})
+	if err != nil {
+		fmt.Printf("Query rewrite failed, using the original question: %v\n", err)
+		return question
+	}
+	rewritten := strings.TrimSpace(resp.Content)
+	if rewritten == "" {
+		return question
+	}
+	return rewritten
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
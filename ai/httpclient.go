@@ -0,0 +1,299 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultAIMaxRetries       = 3
+	defaultAIBreakerThreshold = 5
+	defaultAIBreakerCooldown  = 30 * time.Second
+
+	baseRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff  = 8 * time.Second
+	// maxRetryAfter is the longest Retry-After we wait for. A backend asking
+	// for more is treated as down, since a user is waiting on the answer.
+	maxRetryAfter = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling the backend while it is
+// considered down.
+var ErrCircuitOpen = errors.New("AI backend unavailable: circuit breaker is open")
+
+// APIError is a non-2xx response from an AI backend.
+type APIError struct {
+	Provider   string
+	Path       string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error on %s: status %d body: %s", e.Provider, e.Path, e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the same request may succeed later: the backend
+// is rate limiting or failing, not rejecting the request itself.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// apiClient is the HTTP client every AI provider posts JSON through. Rate
+// limits, 5xx responses and network errors are retried with exponential
+// backoff, honouring Retry-After, and a circuit breaker shared by all
+// clients of the same backend stops calls after repeated failures, so a
+// dead backend fails fast instead of holding every chat for minutes.
+type apiClient struct {
+	provider string
+	baseURL  string
+	headers  map[string]string
+	client   *http.Client
+	retries  int
+	breaker  *circuitBreaker
+}
+
+func newAPIClient(config util.Config, provider, baseURL string, timeout time.Duration, headers map[string]string) *apiClient {
+	retries := config.AIMaxRetries
+	if retries <= 0 {
+		retries = defaultAIMaxRetries
+	}
+	return &apiClient{
+		provider: provider,
+		baseURL:  baseURL,
+		headers:  headers,
+		client:   &http.Client{Timeout: timeout},
+		retries:  retries,
+		breaker:  sharedBreaker(config, provider+" "+baseURL),
+	}
+}
+
+// do posts payload to path and returns the response once the status is
+// below 400. Retries only happen before a response is handed back, so a
+// stream that has started is never replayed.
+func (c *apiClient) do(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, path, b)
+		if err == nil {
+			c.breaker.record(true)
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the backend.
+			c.breaker.abandon()
+			return nil, err
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.Temporary() {
+			// The backend answered; the request was at fault, not the backend.
+			c.breaker.record(true)
+			return nil, err
+		}
+
+		delay := retryDelay(attempt)
+		if apiErr != nil && apiErr.RetryAfter > 0 {
+			delay = apiErr.RetryAfter
+		}
+		if attempt > c.retries || delay > maxRetryAfter {
+			c.breaker.record(false)
+			return nil, err
+		}
+		fmt.Printf("%s request to %s failed, retrying in %s: %v\n", c.provider, path, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.breaker.abandon()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *apiClient) send(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, &APIError{
+			Provider:   c.provider,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Body:       string(bodyBytes),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return resp, nil
+}
+
+func (c *apiClient) post(ctx context.Context, path string, payload interface{}, out interface{}) error {
+	resp, err := c.do(ctx, path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// retryDelay doubles from baseRetryBackoff per attempt, capped at
+// maxRetryBackoff, with up to 20% jitter like job retries.
+func retryDelay(attempt int) time.Duration {
+	d := baseRetryBackoff
+	for i := 1; i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// parseRetryAfter accepts both forms of the header: delay seconds or an HTTP
+// date. It returns 0 when the header is missing or unusable.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker opens after threshold calls in a row have failed. While
+// open, calls fail with ErrCircuitOpen; after cooldown a single probe call
+// is let through, and its outcome closes or reopens the circuit.
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*circuitBreaker)
+)
+
+// sharedBreaker returns the breaker for a backend, so the completion and
+// embedding clients of one server trip together.
+func sharedBreaker(config util.Config, name string) *circuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	if cb, ok := breakers[name]; ok {
+		return cb
+	}
+	cb := newCircuitBreaker(name, config.AIBreakerThreshold, config.AIBreakerCooldown)
+	breakers[name] = cb
+	return cb
+}
+
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultAIBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultAIBreakerCooldown
+	}
+	return &circuitBreaker{name: name, threshold: threshold, cooldown: cooldown}
+}
+
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case breakerOpen:
+		if time.Since(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// A probe is already out.
+		return false
+	}
+	return true
+}
+
+// abandon gives up a half-open probe whose caller went away, so the next
+// call probes instead.
+func (cb *circuitBreaker) abandon() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == breakerHalfOpen {
+		cb.state = breakerOpen
+		cb.openedAt = time.Now().Add(-cb.cooldown)
+	}
+}
+
+func (cb *circuitBreaker) record(ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if ok {
+		if cb.state != breakerClosed {
+			fmt.Printf("AI backend %s recovered, closing circuit\n", cb.name)
+		}
+		cb.state = breakerClosed
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.state == breakerHalfOpen || (cb.state == breakerClosed && cb.failures >= cb.threshold) {
+		fmt.Printf("AI backend %s failed %d times, opening circuit for %s\n", cb.name, cb.failures, cb.cooldown)
+		cb.state = breakerOpen
+		cb.openedAt = time.Now()
+	}
+}
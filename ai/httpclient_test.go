@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"0", 0},
+		{"-3", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"Mon, 01 Dec 2025 10:00:30 GMT", 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAPIErrorTemporary(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		if got := (&APIError{StatusCode: tt.status}).Temporary(); got != tt.want {
+			t.Errorf("Temporary() for status %d = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{1, baseRetryBackoff},
+		{2, 2 * baseRetryBackoff},
+		{3, 4 * baseRetryBackoff},
+		{10, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := retryDelay(tt.attempt); got < tt.base || got > tt.base+tt.base/5 {
+				t.Fatalf("retryDelay(%d) = %s, want between %s and %s", tt.attempt, got, tt.base, tt.base+tt.base/5)
+			}
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	// Each step does one thing to the breaker and checks its state after.
+	// "cooldown" moves the opening back past the cooldown; "allow" also
+	// checks the answer.
+	type step struct {
+		do        string
+		wantAllow bool
+		want      breakerState
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "opens after threshold failures in a row",
+			steps: []step{
+				{do: "fail", want: breakerClosed},
+				{do: "fail", want: breakerClosed},
+				{do: "allow", wantAllow: true, want: breakerClosed},
+				{do: "fail", want: breakerOpen},
+				{do: "allow", wantAllow: false, want: breakerOpen},
+			},
+		},
+		{
+			name: "a success resets the count",
+			steps: []step{
+				{do: "fail", want: breakerClosed},
+				{do: "fail", want: breakerClosed},
+				{do: "ok", want: breakerClosed},
+				{do: "fail", want: breakerClosed},
+				{do: "fail", want: breakerClosed},
+				{do: "fail", want: breakerOpen},
+			},
+		},
+		{
+			name: "one probe after the cooldown closes on success",
+			steps: []step{
+				{do: "fail"}, {do: "fail"}, {do: "fail", want: breakerOpen},
+				{do: "cooldown", want: breakerOpen},
+				{do: "allow", wantAllow: true, want: breakerHalfOpen},
+				{do: "allow", wantAllow: false, want: breakerHalfOpen},
+				{do: "ok", want: breakerClosed},
+				{do: "allow", wantAllow: true, want: breakerClosed},
+			},
+		},
+		{
+			name: "a failed probe reopens at once",
+			steps: []step{
+				{do: "fail"}, {do: "fail"}, {do: "fail", want: breakerOpen},
+				{do: "cooldown", want: breakerOpen},
+				{do: "allow", wantAllow: true, want: breakerHalfOpen},
+				{do: "fail", want: breakerOpen},
+				{do: "allow", wantAllow: false, want: breakerOpen},
+			},
+		},
+		{
+			name: "an abandoned probe lets the next call probe",
+			steps: []step{
+				{do: "fail"}, {do: "fail"}, {do: "fail", want: breakerOpen},
+				{do: "cooldown", want: breakerOpen},
+				{do: "allow", wantAllow: true, want: breakerHalfOpen},
+				{do: "abandon", want: breakerOpen},
+				{do: "allow", wantAllow: true, want: breakerHalfOpen},
+			},
+		},
+		{
+			name: "abandon does nothing to a closed circuit",
+			steps: []step{
+				{do: "fail", want: breakerClosed},
+				{do: "abandon", want: breakerClosed},
+				{do: "allow", wantAllow: true, want: breakerClosed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := newCircuitBreaker("test", 3, time.Minute)
+			for i, s := range tt.steps {
+				switch s.do {
+				case "fail":
+					cb.record(false)
+				case "ok":
+					cb.record(true)
+				case "abandon":
+					cb.abandon()
+				case "cooldown":
+					cb.openedAt = cb.openedAt.Add(-cb.cooldown)
+				case "allow":
+					if got := cb.allow(); got != s.wantAllow {
+						t.Fatalf("step %d: allow() = %v, want %v", i+1, got, s.wantAllow)
+					}
+				}
+				if cb.state != s.want {
+					t.Fatalf("step %d (%s): state %d, want %d", i+1, s.do, cb.state, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCircuitBreakerDefaults(t *testing.T) {
+	cb := newCircuitBreaker("test", 0, 0)
+	if cb.threshold != defaultAIBreakerThreshold || cb.cooldown != defaultAIBreakerCooldown {
+		t.Errorf("threshold %d, cooldown %s, want %d and %s", cb.threshold, cb.cooldown, defaultAIBreakerThreshold, defaultAIBreakerCooldown)
+	}
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ChunkStatusReady = "ready"
+	ChunkStatusStale = "stale"
+)
+
+// IngestOptions describes where a file came from. SourcePath defaults to the
+// path being read; uploads set it to their storage key since they are read
+// from a temporary copy. Mime picks the loader, falling back to the file's
+// extension when it is empty.
+type IngestOptions struct {
+	SourceID   uuid.UUID
+	SourcePath string
+	Filename   string
+	Mime       string
+	Department string
+	Language   string
+	CreatedBy  uuid.UUID
+}
+
+type IngestReport struct {
+	Added     int `json:"added"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+}
+
+func (r IngestReport) String() string {
+	return fmt.Sprintf("%d added, %d unchanged, %d removed", r.Added, r.Unchanged, r.Removed)
+}
+
+// SourceRef identifies the document a chunk came from. Uploaded files have
+// an ID; files ingested straight from disk are known by their path only.
+type SourceRef struct {
+	ID   uuid.UUID
+	Path string
+}
+
+type ChunkRef struct {
+	ID     uuid.UUID
+	Hash   string
+	Status string
+}
+
+// chunkHash covers everything that would change the stored row or its
+// embedding, so switching embedding models re-embeds the whole source.
+func chunkHash(model string, c Chunk) string {
+	h := sha256.New()
+	for _, part := range []string{
+		model,
+		strconv.Itoa(c.SourcePage),
+		c.HeadingPath,
+		c.Department,
+		c.Language,
+		strings.Join(strings.Fields(c.Text), " "),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
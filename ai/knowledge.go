@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	// KnowledgeMime marks chunks that came from an approved admin answer
+	// rather than an uploaded document.
+	KnowledgeMime     = "text/x-support-answer"
+	knowledgeFilename = "Support answer"
+
+	uniqueViolation = "23505"
+)
+
+var ErrKnowledgeExists = errors.New("this answer is already in the knowledge base")
+
+// KnowledgeBase turns question and answer pairs from support chats into
+// chunks, so the bot can answer the next user who asks the same thing.
+// Each entry is a row in ai_knowledge, for the record, and a chunk in the
+// vector store, which is what retrieval searches.
+type KnowledgeBase struct {
+	config      util.Config
+	store       *db.SQLStore
+	vectorStore VectorStore
+	embedder    EmbeddingProvider
+}
+
+func NewKnowledgeBase(config util.Config, store *db.SQLStore, vectorStore VectorStore, embedder EmbeddingProvider) *KnowledgeBase {
+	return &KnowledgeBase{
+		config:      config,
+		store:       store,
+		vectorStore: vectorStore,
+		embedder:    embedder,
+	}
+}
+
+// PromoteParams is an approved pair. Question and Answer are what gets
+// stored, which lets the admin tidy up the wording or strip personal details
+// from the messages first.
+type PromoteParams struct {
+	Chat              db.Chat
+	QuestionMessageID uuid.UUID
+	AnswerMessageID   uuid.UUID
+	Question          string
+	Answer            string
+	CreatedBy         uuid.UUID
+}
+
+// KnowledgeMeta is kept in source_meta.
+type KnowledgeMeta struct {
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+	Department string `json:"department,omitempty"`
+	Language   string `json:"language,omitempty"`
+}
+
+func (k *KnowledgeBase) Promote(ctx context.Context, p PromoteParams) (db.AiKnowledge, error) {
+	answerID := pgtype.UUID{Bytes: p.AnswerMessageID, Valid: true}
+	_, err := k.store.Querier.GetKnowledgeByAnswerMessage(ctx, answerID)
+	if err == nil {
+		return db.AiKnowledge{}, ErrKnowledgeExists
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.AiKnowledge{}, err
+	}
+
+	meta := KnowledgeMeta{
+		Question:   strings.TrimSpace(p.Question),
+		Answer:     strings.TrimSpace(p.Answer),
+		Department: p.Chat.Department.String,
+		Language:   detectLanguage(p.Question),
+	}
+	chunk := Chunk{
+		Text:           knowledgeText(meta.Question, meta.Answer),
+		SourcePath:     "knowledge/" + p.AnswerMessageID.String(),
+		SourceFilename: knowledgeFilename,
+		SourceMime:     KnowledgeMime,
+		Department:     meta.Department,
+		Language:       meta.Language,
+		CreatedBy:      p.CreatedBy,
+	}
+	chunk.Hash = chunkHash(k.embedder.Model(), chunk)
+
+	vecs, err := k.embedder.Embed(ctx, []string{chunk.embeddingText()})
+	if err != nil {
+		return db.AiKnowledge{}, fmt.Errorf("cannot embed answer: %w", err)
+	}
+	if len(vecs) != 1 {
+		return db.AiKnowledge{}, fmt.Errorf("embeddings count mismatch: got %d for 1 text", len(vecs))
+	}
+	chunk.Embedding = vecs[0]
+
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return db.AiKnowledge{}, err
+	}
+	// The row goes in first, so the unique index on the answer settles two
+	// admins promoting the same answer at once before any chunk exists.
+	chunk.ID = uuid.New()
+	knowledge, err := k.store.Querier.CreateKnowledge(ctx, db.CreateKnowledgeParams{
+		SourceChunkID:     pgtype.UUID{Bytes: chunk.ID, Valid: true},
+		SourceText:        chunk.Text,
+		Column3:           metaJSON,
+		EmbeddingVector:   chunk.Embedding,
+		CreatedBy:         pgtype.UUID{Bytes: p.CreatedBy, Valid: p.CreatedBy != uuid.Nil},
+		ChatExternalID:    pgtype.UUID{Bytes: p.Chat.ChatExternalID, Valid: true},
+		QuestionMessageID: pgtype.UUID{Bytes: p.QuestionMessageID, Valid: p.QuestionMessageID != uuid.Nil},
+		AnswerMessageID:   answerID,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return db.AiKnowledge{}, ErrKnowledgeExists
+		}
+		return db.AiKnowledge{}, err
+	}
+
+	if err := k.vectorStore.Add(ctx, []Chunk{chunk}); err != nil {
+		if delErr := k.store.Querier.DeleteKnowledge(ctx, knowledge.KnowledgeExternalID); delErr != nil {
+			return db.AiKnowledge{}, fmt.Errorf("cannot index answer: %w (and cannot remove its entry: %v)", err, delErr)
+		}
+		return db.AiKnowledge{}, fmt.Errorf("cannot index answer: %w", err)
+	}
+	return knowledge, nil
+}
+
+// Remove takes an entry out of retrieval and deletes it.
+func (k *KnowledgeBase) Remove(ctx context.Context, knowledge db.AiKnowledge) error {
+	if knowledge.SourceChunkID.Valid {
+		err := k.vectorStore.SetStatus(ctx, []uuid.UUID{knowledge.SourceChunkID.Bytes}, ChunkStatusStale)
+		if err != nil {
+			return err
+		}
+	}
+	return k.store.Querier.DeleteKnowledge(ctx, knowledge.KnowledgeExternalID)
+}
+
+// knowledgeText is what gets embedded and shown to the model: the question
+// makes the pair land near similar questions, the answer is what is used.
+func knowledgeText(question, answer string) string {
+	return "Question: " + question + "\nAnswer: " + answer
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	LanguagePersian = "fa"
+	LanguageArabic  = "ar"
+	LanguageEnglish = "en"
+)
+
+var languageNames = map[string]string{
+	LanguagePersian: "Persian",
+	LanguageArabic:  "Arabic",
+	LanguageEnglish: "English",
+}
+
+// Letters that exist in Persian but not in Arabic script as used for Arabic.
+var persianOnly = map[rune]bool{'پ': true, 'چ': true, 'ژ': true, 'گ': true, 'ک': true, 'ی': true}
+
+// Letters that Arabic uses and Persian spells differently or not at all.
+var arabicOnly = map[rune]bool{'ة': true, 'ي': true, 'ك': true, 'ى': true}
+
+// detectLanguage makes a cheap guess from the script of the letters in text.
+// It returns "" when there are no letters to judge by, or when Arabic script
+// has none of the letters that tell Persian and Arabic apart, as in "سلام".
+func detectLanguage(text string) string {
+	var arabicScript, latin, persian, arabic int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Arabic, r):
+			arabicScript++
+			if persianOnly[r] {
+				persian++
+			}
+			if arabicOnly[r] {
+				arabic++
+			}
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	switch {
+	case arabicScript == 0 && latin == 0:
+		return ""
+	case arabicScript >= latin:
+		if persian >= arabic && persian > 0 {
+			return LanguagePersian
+		}
+		if arabic > 0 {
+			return LanguageArabic
+		}
+		return ""
+	default:
+		return LanguageEnglish
+	}
+}
+
+// languagePolicy decides which language the bot answers in.
+type languagePolicy struct {
+	fallback string
+	allowed  map[string]bool
+}
+
+func newLanguagePolicy(config util.Config) languagePolicy {
+	p := languagePolicy{
+		fallback: strings.ToLower(strings.TrimSpace(config.BotDefaultLanguage)),
+		allowed:  map[string]bool{},
+	}
+	if p.fallback == "" {
+		p.fallback = LanguagePersian
+	}
+	for _, lang := range config.BotLanguages {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			p.allowed[lang] = true
+		}
+	}
+	return p
+}
+
+// replyLanguage returns the language of question, or of the user's earlier
+// turns when the question is too short to tell ("ok", "سلام"). Languages the
+// operator has not allowed fall back to the default.
+func (p languagePolicy) replyLanguage(question string, history []ChatMessage) string {
+	lang := detectLanguage(question)
+	for i := len(history) - 1; lang == "" && i >= 0; i-- {
+		if history[i].Role == "user" {
+			lang = detectLanguage(history[i].Content)
+		}
+	}
+	if lang == "" || (len(p.allowed) > 0 && !p.allowed[lang]) {
+		return p.fallback
+	}
+	return lang
+}
+
+func languageName(lang string) string {
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+	return lang
+}
@@ -0,0 +1,181 @@
+package ai
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	MimeText     = "text/plain"
+	MimePDF      = "application/pdf"
+	MimeDOCX     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeHTML     = "text/html"
+	MimeMarkdown = "text/markdown"
+	MimeCSV      = "text/csv"
+)
+
+// Page is one unit of loaded text. Number is the 1-based PDF page, or 0 for
+// formats without pages. A Whole page is stored as a single chunk instead of
+// going through the splitter; CSV rows use this so each FAQ stays intact.
+type Page struct {
+	Number int
+	Text   string
+	Whole  bool
+}
+
+// Loader extracts text from a file on disk.
+type Loader func(path string) ([]Page, error)
+
+var (
+	loadersMu     sync.RWMutex
+	loaders       = map[string]Loader{}
+	extensionMime = map[string]string{}
+)
+
+func init() {
+	RegisterLoader(MimeText, loadTextFile, ".txt")
+	RegisterLoader(MimePDF, loadPDFFile, ".pdf")
+	RegisterLoader(MimeDOCX, loadDOCXFile, ".docx")
+	RegisterLoader(MimeHTML, loadHTMLFile, ".html", ".htm")
+	RegisterLoader(MimeMarkdown, loadMarkdownFile, ".md", ".markdown")
+	RegisterLoader(MimeCSV, loadCSVFile, ".csv")
+}
+
+// RegisterLoader adds or replaces the loader for a MIME type and maps the
+// given file extensions to it.
+func RegisterLoader(mime string, loader Loader, extensions ...string) {
+	loadersMu.Lock()
+	defer loadersMu.Unlock()
+	loaders[mime] = loader
+	for _, ext := range extensions {
+		extensionMime[strings.ToLower(ext)] = mime
+	}
+}
+
+// MimeForFile returns the MIME type registered for the file's extension, or
+// "" when no loader handles it.
+func MimeForFile(filename string) string {
+	loadersMu.RLock()
+	defer loadersMu.RUnlock()
+	return extensionMime[strings.ToLower(filepath.Ext(filename))]
+}
+
+// DetectMime works out which loader an upload needs. The content wins when
+// it is recognisable, so a PDF named "report" or "notes.txt" is still read as
+// a PDF; then the extension, then the type the client declared. It returns ""
+// when no loader handles the file.
+func DetectMime(filename, declared string, head []byte) string {
+	sniffed := normalizeMime(http.DetectContentType(head))
+	if sniffed != MimeText && hasLoader(sniffed) {
+		return sniffed
+	}
+	if mime := MimeForFile(filename); mime != "" {
+		return mime
+	}
+	if declared = normalizeMime(declared); hasLoader(declared) {
+		return declared
+	}
+	if sniffed == MimeText {
+		return MimeText
+	}
+	return ""
+}
+
+// normalizeMime drops parameters such as "; charset=utf-8".
+func normalizeMime(mime string) string {
+	mime, _, _ = strings.Cut(mime, ";")
+	return strings.ToLower(strings.TrimSpace(mime))
+}
+
+func hasLoader(mime string) bool {
+	loadersMu.RLock()
+	defer loadersMu.RUnlock()
+	_, ok := loaders[mime]
+	return ok
+}
+
+// loadFile reads path with the loader for mime, or with the one registered
+// for its extension when mime is empty or unknown.
+func loadFile(path, mime string) ([]Page, string, error) {
+	mime = normalizeMime(mime)
+	if !hasLoader(mime) {
+		mime = MimeForFile(path)
+	}
+	loadersMu.RLock()
+	loader, ok := loaders[mime]
+	loadersMu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported file format")
+	}
+	pages, err := loader(path)
+	return pages, mime, err
+}
+
+func loadTextFile(path string) ([]Page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var b strings.Builder
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		b.WriteString(scanner.Text())
+		b.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return []Page{{Number: 0, Text: b.String()}}, nil
+}
+
+func loadPDFFile(path string) ([]Page, error) {
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var pages []Page
+	totalPage := r.NumPage()
+	for i := 1; i <= totalPage; i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		txt, err := p.GetPlainText(nil)
+		if err != nil {
+			continue
+		}
+		pages = append(pages, Page{Number: i, Text: txt})
+	}
+	return pages, nil
+}
+
+// collapseBlankLines trims every line and keeps at most one empty line in a
+// row, which is what the structured loaders want after stripping markup.
+func collapseBlankLines(text string) string {
+	var b strings.Builder
+	blank := true
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank {
+				b.WriteString("\n")
+			}
+			blank = true
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		blank = false
+	}
+	return strings.TrimSpace(b.String())
+}
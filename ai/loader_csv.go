@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// loadCSVFile treats the first row as the header and turns every following
+// row into its own whole page of "Header: value" lines, so one FAQ entry is
+// one chunk.
+func loadCSVFile(path string) ([]Page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	var pages []Page
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			name := fmt.Sprintf("Column %d", i+1)
+			if i < len(header) && header[i] != "" {
+				name = header[i]
+			}
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+		if b.Len() > 0 {
+			pages = append(pages, Page{Number: 0, Text: strings.TrimSpace(b.String()), Whole: true})
+		}
+	}
+	return pages, nil
+}
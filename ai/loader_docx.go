@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// loadDOCXFile reads word/document.xml straight from the zip. Paragraphs
+// are separated by blank lines, Heading styles become Markdown headings and table cells are
+// joined with " | " so rows stay readable.
+func loadDOCXFile(path string) ([]Page, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+			break
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("not a word document: word/document.xml missing")
+	}
+	rc, err := doc.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	text, err := docxText(rc)
+	if err != nil {
+		return nil, err
+	}
+	return []Page{{Number: 0, Text: text}}, nil
+}
+
+func docxText(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+	var out, para strings.Builder
+	heading := 0
+	inRow, firstCell := false, true
+
+	flush := func() {
+		line := strings.Join(strings.Fields(para.String()), " ")
+		para.Reset()
+		if line == "" {
+			return
+		}
+		if heading > 0 {
+			out.WriteString(strings.Repeat("#", heading) + " ")
+		}
+		out.WriteString(line)
+		// Rows of one table stay together; everything else is a paragraph.
+		if inRow {
+			out.WriteString("\n")
+		} else {
+			out.WriteString("\n\n")
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				heading = 0
+			case "pStyle":
+				heading = headingLevel(attr(t, "val"))
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString(" ")
+			case "tr":
+				inRow, firstCell = true, true
+			case "tc":
+				if !firstCell {
+					para.WriteString(" | ")
+				}
+				firstCell = false
+			case "t":
+				var s string
+				if err := dec.DecodeElement(&s, &t); err != nil {
+					return "", err
+				}
+				para.WriteString(s)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				// Paragraphs inside a table cell belong to the row line.
+				if inRow {
+					para.WriteString(" ")
+				} else {
+					flush()
+				}
+			case "tr":
+				flush()
+				inRow = false
+			case "tbl":
+				out.WriteString("\n")
+			}
+		}
+	}
+	flush()
+	return collapseBlankLines(out.String()), nil
+}
+
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// headingLevel maps Word style IDs such as "Heading2" to 2, and "Title" to 1.
+func headingLevel(style string) int {
+	s := strings.ToLower(style)
+	if s == "title" {
+		return 1
+	}
+	if !strings.HasPrefix(s, "heading") {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "heading"))
+	if err != nil || n < 1 {
+		return 0
+	}
+	if n > 6 {
+		n = 6
+	}
+	return n
+}
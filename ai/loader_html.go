@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Elements whose content is page chrome or code rather than article text.
+var htmlSkipped = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"svg": true, "iframe": true,
+}
+
+var htmlBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"br": true, "hr": true, "ul": true, "ol": true, "table": true, "tr": true,
+	"blockquote": true, "pre": true, "dl": true, "dt": true, "dd": true,
+}
+
+// loadHTMLFile keeps the text of an exported help-center page: headings as
+// Markdown headings, list items as "- " lines and table cells joined with
+// " | ", with navigation, scripts and styles dropped.
+func loadHTMLFile(path string) ([]Page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	root, err := html.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	writeHTMLText(&b, root)
+	return []Page{{Number: 0, Text: collapseBlankLines(b.String())}}, nil
+}
+
+func writeHTMLText(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text != "" {
+			b.WriteString(text)
+			b.WriteString(" ")
+		}
+		return
+	case html.ElementNode:
+		if htmlSkipped[n.Data] {
+			return
+		}
+		switch n.Data {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			b.WriteString("\n" + strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		case "li":
+			b.WriteString("\n- ")
+		case "td", "th":
+			if n.PrevSibling != nil {
+				b.WriteString("| ")
+			}
+		default:
+			if htmlBlocks[n.Data] {
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeHTMLText(b, c)
+	}
+
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "h1", "h2", "h3", "h4", "h5", "h6", "li":
+			b.WriteString("\n")
+		default:
+			if htmlBlocks[n.Data] {
+				b.WriteString("\n")
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdEmphasis = regexp.MustCompile(`(\*\*|\*|~~|` + "`" + `)([^*~` + "`" + `\n]+)(\*\*|\*|~~|` + "`" + `)`)
+	mdComment  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	mdRule     = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+)
+
+// loadMarkdownFile keeps headings and list markers, since they carry the
+// structure the splitter uses, and strips inline markup down to its text.
+func loadMarkdownFile(path string) ([]Page, error) {
+	pages, err := loadTextFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pages {
+		pages[i].Text = cleanMarkdown(pages[i].Text)
+	}
+	return pages, nil
+}
+
+func cleanMarkdown(text string) string {
+	text = mdComment.ReplaceAllString(text, "")
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") || mdRule.MatchString(trimmed) {
+			b.WriteString("\n")
+			continue
+		}
+		line = mdImage.ReplaceAllString(line, "$1")
+		line = mdLink.ReplaceAllString(line, "$1")
+		line = mdEmphasis.ReplaceAllString(line, "$2")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return collapseBlankLines(b.String())
+}
@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryVectorStore keeps every chunk in process memory. Nothing is
+// persisted, so it suits tests and small deployments that ingest on boot.
+type MemoryVectorStore struct {
+	mu     sync.RWMutex
+	chunks []Chunk
+}
+
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{}
+}
+
+func (s *MemoryVectorStore) Add(ctx context.Context, chunks []Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range chunks {
+		if chunks[i].ID == uuid.Nil {
+			chunks[i].ID = uuid.New()
+		}
+		if chunks[i].Status == "" {
+			chunks[i].Status = ChunkStatusReady
+		}
+		s.chunks = append(s.chunks, chunks[i])
+	}
+	return nil
+}
+
+func (s *MemoryVectorStore) Search(ctx context.Context, embedding []float64, topK int, filter SearchFilter) ([]ScoredChunk, error) {
+	if topK <= 0 {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h := make(scoredHeap, 0, topK)
+	for _, c := range s.chunks {
+		if c.Status != ChunkStatusReady || !filter.matches(c) {
+			continue
+		}
+		score := cosineSim(embedding, c.Embedding)
+		if len(h) < topK {
+			heap.Push(&h, ScoredChunk{Chunk: c, Score: score})
+			continue
+		}
+		if score > h[0].Score {
+			h[0] = ScoredChunk{Chunk: c, Score: score}
+			heap.Fix(&h, 0)
+		}
+	}
+
+	out := []ScoredChunk(h)
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out, nil
+}
+
+// SearchText scores chunks by how many distinct query terms they contain.
+func (s *MemoryVectorStore) SearchText(ctx context.Context, query string, topK int, filter SearchFilter) ([]ScoredChunk, error) {
+	terms := queryTerms(query)
+	if topK <= 0 || len(terms) == 0 {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []ScoredChunk
+	for _, c := range s.chunks {
+		if c.Status != ChunkStatusReady || !filter.matches(c) {
+			continue
+		}
+		words := make(map[string]bool)
+		for _, w := range queryTerms(c.Text) {
+			words[w] = true
+		}
+		matched := 0
+		for _, t := range terms {
+			if words[t] {
+				matched++
+			}
+		}
+		if matched > 0 {
+			out = append(out, ScoredChunk{Chunk: c, Score: float64(matched) / float64(len(terms))})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > topK {
+		out = out[:topK]
+	}
+	return out, nil
+}
+
+func (s *MemoryVectorStore) SourceChunks(ctx context.Context, source SourceRef) ([]ChunkRef, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []ChunkRef
+	for _, c := range s.chunks {
+		if source.ID != uuid.Nil && c.SourceID != source.ID {
+			continue
+		}
+		if source.ID == uuid.Nil && (c.SourceID != uuid.Nil || c.SourcePath != source.Path) {
+			continue
+		}
+		out = append(out, ChunkRef{ID: c.ID, Hash: c.Hash, Status: c.Status})
+	}
+	return out, nil
+}
+
+func (s *MemoryVectorStore) SetStatus(ctx context.Context, ids []uuid.UUID, status string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	want := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chunks {
+		if want[s.chunks[i].ID] {
+			s.chunks[i].Status = status
+		}
+	}
+	return nil
+}
+
+// scoredHeap is a min-heap on Score, so the weakest of the current top K is
+// always at index 0 and can be replaced in O(log K).
+type scoredHeap []ScoredChunk
+
+func (h scoredHeap) Len() int            { return len(h) }
+func (h scoredHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h scoredHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoredHeap) Push(x interface{}) { *h = append(*h, x.(ScoredChunk)) }
+func (h *scoredHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
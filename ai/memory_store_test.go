@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var memorySourceID = uuid.MustParse("5f1c9a52-7d3e-4b8a-9c61-2e4f0b7a8d13")
+
+// newTestMemoryStore holds chunks whose cosine similarity to [1, 0] falls
+// from a to d; e would come second but is stale.
+func newTestMemoryStore(t *testing.T) *MemoryVectorStore {
+	t.Helper()
+	s := NewMemoryVectorStore()
+	err := s.Add(context.Background(), []Chunk{
+		{SourceFilename: "a", Text: "refund policy", Embedding: []float64{1, 0}, Department: "billing", Language: LanguageEnglish},
+		{SourceFilename: "b", Text: "بازپرداخت", Embedding: []float64{1, 1}, Language: LanguagePersian},
+		{SourceFilename: "c", Text: "reset router", Embedding: []float64{0, 1}, Department: "tech"},
+		{SourceFilename: "d", Text: "invoice refund", Embedding: []float64{-1, 0}, Department: "billing", Language: LanguageEnglish, SourceID: memorySourceID},
+		{SourceFilename: "e", Text: "refund draft", Embedding: []float64{1, 0.1}, Status: ChunkStatusStale},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func chunkNames(hits []ScoredChunk) []string {
+	var names []string
+	for _, h := range hits {
+		names = append(names, h.SourceFilename)
+	}
+	return names
+}
+
+func TestMemoryVectorStoreSearch(t *testing.T) {
+	s := newTestMemoryStore(t)
+	tests := []struct {
+		name   string
+		topK   int
+		filter SearchFilter
+		want   []string
+	}{
+		{name: "top k", topK: 2, want: []string{"a", "b"}},
+		{name: "k above the number of chunks", topK: 10, want: []string{"a", "b", "c", "d"}},
+		{name: "zero k", topK: 0},
+		{name: "department keeps general knowledge", topK: 10, filter: SearchFilter{Department: "billing"}, want: []string{"a", "b", "d"}},
+		{name: "department applies before k", topK: 2, filter: SearchFilter{Department: "tech"}, want: []string{"b", "c"}},
+		{name: "unknown department", topK: 10, filter: SearchFilter{Department: "sales"}, want: []string{"b"}},
+		{name: "language keeps undetected chunks", topK: 10, filter: SearchFilter{Language: LanguageEnglish}, want: []string{"a", "c", "d"}},
+		{name: "other language", topK: 10, filter: SearchFilter{Language: LanguagePersian}, want: []string{"b", "c"}},
+		{name: "department and language", topK: 10, filter: SearchFilter{Department: "billing", Language: LanguagePersian}, want: []string{"b"}},
+		{name: "scope by department keeps general knowledge", topK: 10, filter: SearchFilter{Departments: []string{"tech"}}, want: []string{"b", "c"}},
+		{name: "scope by source only", topK: 10, filter: SearchFilter{Sources: []uuid.UUID{memorySourceID}}, want: []string{"d"}},
+		{name: "scope by department or source", topK: 10, filter: SearchFilter{Departments: []string{"tech"}, Sources: []uuid.UUID{memorySourceID}}, want: []string{"b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := s.Search(context.Background(), []float64{1, 0}, tt.topK, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := chunkNames(hits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryVectorStoreSearchText(t *testing.T) {
+	s := newTestMemoryStore(t)
+	tests := []struct {
+		name  string
+		query string
+		topK  int
+		want  []string
+	}{
+		{name: "more matched terms rank first", query: "refund invoice", topK: 10, want: []string{"d", "a"}},
+		{name: "top k", query: "refund invoice", topK: 1, want: []string{"d"}},
+		{name: "no match", query: "warranty", topK: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := s.SearchText(context.Background(), tt.query, tt.topK, SearchFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := chunkNames(hits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryVectorStoreSetStatus(t *testing.T) {
+	s := newTestMemoryStore(t)
+	ctx := context.Background()
+	refs, err := s.SourceChunks(ctx, SourceRef{ID: memorySourceID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("got %d chunks of the source, want 1", len(refs))
+	}
+	if err := s.SetStatus(ctx, []uuid.UUID{refs[0].ID}, ChunkStatusStale); err != nil {
+		t.Fatal(err)
+	}
+	hits, err := s.Search(ctx, []float64{1, 0}, 10, SearchFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := chunkNames(hits), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() after SetStatus = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultOllamaBaseURL        = "http://localhost:11434"
+	defaultOllamaChatModel      = "llama3.1"
+	defaultOllamaEmbeddingModel = "nomic-embed-text"
+)
+
+type ollamaClient struct {
+	*apiClient
+}
+
+type ollamaCompletion struct {
+	ollamaClient
+	model string
+	// contextTokens is sent as num_ctx: Ollama silently cuts prompts to its
+	// own default window otherwise, whatever the context builder packed.
+	contextTokens int
+}
+
+type ollamaEmbedding struct {
+	ollamaClient
+	model string
+}
+
+func newOllamaClient(config util.Config) ollamaClient {
+	baseURL := strings.TrimRight(config.AIBaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	return ollamaClient{newAPIClient(config, ProviderOllama, baseURL, 300*time.Second, nil)}
+}
+
+func newOllamaCompletion(config util.Config) *ollamaCompletion {
+	model := config.AIChatModel
+	if model == "" {
+		model = defaultOllamaChatModel
+	}
+	return &ollamaCompletion{
+		ollamaClient:  newOllamaClient(config),
+		model:         model,
+		contextTokens: contextBudget(config, model),
+	}
+}
+
+func newOllamaEmbedding(config util.Config) *ollamaEmbedding {
+	model := config.AIEmbeddingModel
+	if model == "" {
+		model = defaultOllamaEmbeddingModel
+	}
+	return &ollamaEmbedding{ollamaClient: newOllamaClient(config), model: model}
+}
+
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaMessage        `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+	Tools    []ollamaTool           `json:"tools,omitempty"`
+}
+
+// ollamaMessage differs from OpenAI's: tool calls have no ID, arguments are
+// an object, and a tool result names the tool instead of the call.
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+}
+
+func (p *ollamaCompletion) Model() string {
+	return p.model
+}
+
+func (p *ollamaCompletion) chatRequest(req CompletionRequest, stream bool) ollamaChatRequest {
+	options := map[string]interface{}{
+		"temperature": req.Temperature,
+		"num_ctx":     p.contextTokens,
+	}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	out := ollamaChatRequest{Model: p.model, Stream: stream, Options: options}
+	for _, m := range req.Messages {
+		msg := ollamaMessage{Role: m.Role, Content: m.Content, ToolName: m.ToolName}
+		for _, call := range m.ToolCalls {
+			var tc ollamaToolCall
+			tc.Function.Name = call.Name
+			tc.Function.Arguments = call.Arguments
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+		out.Messages = append(out.Messages, msg)
+	}
+	for _, spec := range req.Tools {
+		tool := ollamaTool{Type: "function"}
+		tool.Function.Name = spec.Name
+		tool.Function.Description = spec.Description
+		tool.Function.Parameters = spec.Parameters
+		out.Tools = append(out.Tools, tool)
+	}
+	return out
+}
+
+// ollamaToolCalls numbers the calls, since Ollama gives them no ID.
+func ollamaToolCalls(calls []ollamaToolCall, first int) []ToolCall {
+	var out []ToolCall
+	for i, tc := range calls {
+		args := tc.Function.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+		out = append(out, ToolCall{ID: fmt.Sprintf("call_%d", first+i), Name: tc.Function.Name, Arguments: args})
+	}
+	return out
+}
+
+func (p *ollamaCompletion) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	var cr ollamaChatResponse
+	if err := p.post(ctx, "/api/chat", p.chatRequest(req, false), &cr); err != nil {
+		return CompletionResponse{}, err
+	}
+	return CompletionResponse{Content: cr.Message.Content, ToolCalls: ollamaToolCalls(cr.Message.ToolCalls, 0)}, nil
+}
+
+func (p *ollamaCompletion) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (CompletionResponse, error) {
+	resp, err := p.do(ctx, "/api/chat", p.chatRequest(req, true))
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var full strings.Builder
+	var calls []ToolCall
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var cr ollamaChatResponse
+		if err := json.Unmarshal(line, &cr); err != nil {
+			return CompletionResponse{}, fmt.Errorf("cannot decode stream chunk: %w", err)
+		}
+		calls = append(calls, ollamaToolCalls(cr.Message.ToolCalls, len(calls))...)
+		if cr.Message.Content != "" {
+			full.WriteString(cr.Message.Content)
+			if err := onDelta(cr.Message.Content); err != nil {
+				return CompletionResponse{}, err
+			}
+		}
+		if cr.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return CompletionResponse{}, err
+	}
+	return CompletionResponse{Content: full.String(), ToolCalls: calls}, nil
+}
+
+func (p *ollamaEmbedding) Model() string {
+	return p.model
+}
+
+func (p *ollamaEmbedding) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	type embReq struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	type embResp struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+
+	var er embResp
+	if err := p.post(ctx, "/api/embed", embReq{Model: p.model, Input: texts}, &er); err != nil {
+		return nil, err
+	}
+	if len(er.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(er.Embeddings), len(texts))
+	}
+	return er.Embeddings, nil
+}
@@ -0,0 +1,267 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultOpenAIBaseURL        = "https://api.openai.com/v1"
+	defaultOpenAIChatModel      = "gpt-4o"
+	defaultOpenAIEmbeddingModel = "text-embedding-ada-002"
+
+	// maxStreamToolCalls bounds the tool call index a stream may use, so a
+	// broken or hostile server cannot make Stream allocate without limit.
+	maxStreamToolCalls = 64
+)
+
+type openAIClient struct {
+	*apiClient
+}
+
+type openAICompletion struct {
+	openAIClient
+	model string
+}
+
+type openAIEmbedding struct {
+	openAIClient
+	model string
+}
+
+func newOpenAIClient(config util.Config) openAIClient {
+	baseURL := strings.TrimRight(config.AIBaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	headers := map[string]string{}
+	if config.AIAPIKey != "" {
+		headers["Authorization"] = "Bearer " + config.AIAPIKey
+	}
+	return openAIClient{newAPIClient(config, ProviderOpenAI, baseURL, 120*time.Second, headers)}
+}
+
+func newOpenAICompletion(config util.Config) *openAICompletion {
+	model := config.AIChatModel
+	if model == "" {
+		model = defaultOpenAIChatModel
+	}
+	return &openAICompletion{openAIClient: newOpenAIClient(config), model: model}
+}
+
+func newOpenAIEmbedding(config util.Config) *openAIEmbedding {
+	model := config.AIEmbeddingModel
+	if model == "" {
+		model = defaultOpenAIEmbeddingModel
+	}
+	return &openAIEmbedding{openAIClient: newOpenAIClient(config), model: model}
+}
+
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+	Tools       []openAITool    `json:"tools,omitempty"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIToolCall carries the arguments as a JSON string, not an object.
+// Index only comes back in stream chunks.
+type openAIToolCall struct {
+	Index    int    `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAITool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+func (p *openAICompletion) chatRequest(req CompletionRequest, stream bool) openAIChatRequest {
+	out := openAIChatRequest{
+		Model:       p.model,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stream:      stream,
+	}
+	for _, m := range req.Messages {
+		msg := openAIMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			tc := openAIToolCall{ID: call.ID, Type: "function"}
+			tc.Function.Name = call.Name
+			tc.Function.Arguments = string(call.Arguments)
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+		out.Messages = append(out.Messages, msg)
+	}
+	for _, spec := range req.Tools {
+		tool := openAITool{Type: "function"}
+		tool.Function.Name = spec.Name
+		tool.Function.Description = spec.Description
+		tool.Function.Parameters = spec.Parameters
+		out.Tools = append(out.Tools, tool)
+	}
+	return out
+}
+
+func openAIToolCalls(calls []openAIToolCall) []ToolCall {
+	var out []ToolCall
+	for _, tc := range calls {
+		args := json.RawMessage(tc.Function.Arguments)
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		out = append(out, ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: args})
+	}
+	return out
+}
+
+func (p *openAICompletion) Model() string {
+	return p.model
+}
+
+func (p *openAICompletion) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	type chatResp struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	var cr chatResp
+	if err := p.post(ctx, "/chat/completions", p.chatRequest(req, false), &cr); err != nil {
+		return CompletionResponse{}, err
+	}
+	if len(cr.Choices) == 0 {
+		return CompletionResponse{}, fmt.Errorf("no choices returned from chat API")
+	}
+	msg := cr.Choices[0].Message
+	return CompletionResponse{Content: msg.Content, ToolCalls: openAIToolCalls(msg.ToolCalls)}, nil
+}
+
+// Stream passes text on as it arrives. Tool calls arrive in fragments keyed
+// by index, the name first and the arguments piece by piece, and are
+// returned whole at the end.
+func (p *openAICompletion) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (CompletionResponse, error) {
+	type chunkResp struct {
+		Choices []struct {
+			Delta struct {
+				Content   string           `json:"content"`
+				ToolCalls []openAIToolCall `json:"tool_calls"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+
+	resp, err := p.do(ctx, "/chat/completions", p.chatRequest(req, true))
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var full strings.Builder
+	var calls []openAIToolCall
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var cr chunkResp
+		if err := json.Unmarshal([]byte(data), &cr); err != nil {
+			return CompletionResponse{}, fmt.Errorf("cannot decode stream chunk: %w", err)
+		}
+		for _, c := range cr.Choices {
+			for _, tc := range c.Delta.ToolCalls {
+				if tc.Index < 0 || tc.Index >= maxStreamToolCalls {
+					return CompletionResponse{}, fmt.Errorf("chat API returned out of range tool call index %d", tc.Index)
+				}
+				for len(calls) <= tc.Index {
+					calls = append(calls, openAIToolCall{Index: len(calls)})
+				}
+				call := &calls[tc.Index]
+				if tc.ID != "" {
+					call.ID = tc.ID
+				}
+				call.Function.Name += tc.Function.Name
+				call.Function.Arguments += tc.Function.Arguments
+			}
+			if c.Delta.Content == "" {
+				continue
+			}
+			full.WriteString(c.Delta.Content)
+			if err := onDelta(c.Delta.Content); err != nil {
+				return CompletionResponse{}, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return CompletionResponse{}, err
+	}
+	return CompletionResponse{Content: full.String(), ToolCalls: openAIToolCalls(calls)}, nil
+}
+
+func (p *openAIEmbedding) Model() string {
+	return p.model
+}
+
+func (p *openAIEmbedding) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	type embReq struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	type embResp struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+			Index     int       `json:"index"`
+		} `json:"data"`
+	}
+
+	var er embResp
+	if err := p.post(ctx, "/embeddings", embReq{Model: p.model, Input: texts}, &er); err != nil {
+		return nil, err
+	}
+
+	out := make([][]float64, len(texts))
+	for _, d := range er.Data {
+		if d.Index < 0 || d.Index >= len(out) {
+			return nil, fmt.Errorf("embeddings API returned out of range index %d", d.Index)
+		}
+		if out[d.Index] != nil {
+			return nil, fmt.Errorf("embeddings API returned index %d twice", d.Index)
+		}
+		out[d.Index] = d.Embedding
+	}
+	for i, v := range out {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings API returned no embedding for input %d of %d", i, len(out))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+// MatchPersona picks the persona a chat is answered as. The entry point the
+// client opened the chat from wins, then a keyword in the label, then the
+// chat's department; within each rule personas are tried in slug order.
+// It reports false when none applies and the bot should answer with the
+// settings from app.env.
+func MatchPersona(personas []db.BotPersona, entryPoint, label, department string) (db.BotPersona, bool) {
+	if entryPoint = strings.TrimSpace(entryPoint); entryPoint != "" {
+		for _, p := range personas {
+			for _, e := range p.EntryPoints {
+				if strings.EqualFold(strings.TrimSpace(e), entryPoint) {
+					return p, true
+				}
+			}
+		}
+	}
+
+	text := " " + normalizeForMatch(label) + " "
+	for _, p := range personas {
+		for _, kw := range p.LabelKeywords {
+			if kw = normalizeForMatch(kw); kw != "" && strings.Contains(text, " "+kw+" ") {
+				return p, true
+			}
+		}
+	}
+
+	if department != "" {
+		for _, p := range personas {
+			if slices.Contains(p.Departments, department) {
+				return p, true
+			}
+		}
+	}
+	return db.BotPersona{}, false
+}
+
+// persona is everything the bot answers one chat with. The built-in one,
+// used when no stored persona matches, has no slug or name.
+type persona struct {
+	slug        string
+	name        string
+	avatarURL   string
+	promptName  string
+	departments []string
+	sources     []uuid.UUID
+	temperature float64
+	handoffs    handoffPolicy
+	model       botModel
+}
+
+type botModel struct {
+	completer CompletionProvider
+	contexts  *ContextBuilder
+}
+
+func (b *Bot) builtinPersona() persona {
+	return persona{
+		promptName:  DefaultPromptName,
+		temperature: b.config.AITemperature,
+		handoffs:    b.handoffs,
+		model:       botModel{completer: b.completer, contexts: b.contexts},
+	}
+}
+
+// newPersona fills in whatever the stored persona leaves unset from app.env.
+// Handoff phrases replace the configured ones rather than adding to them,
+// like HANDOFF_PHRASES does for the defaults.
+func (b *Bot) newPersona(stored db.BotPersona) (persona, error) {
+	model, err := b.modelFor(stored.ChatModel.String)
+	if err != nil {
+		return persona{}, err
+	}
+	p := persona{
+		slug:        stored.Slug,
+		name:        stored.DisplayName,
+		avatarURL:   stored.AvatarUrl.String,
+		promptName:  stored.PromptName,
+		departments: stored.Departments,
+		sources:     stored.SourceIds,
+		temperature: b.config.AITemperature,
+		handoffs:    b.handoffs,
+		model:       model,
+	}
+	if p.promptName == "" {
+		p.promptName = DefaultPromptName
+	}
+	if stored.Temperature.Valid {
+		p.temperature = stored.Temperature.Float64
+	}
+	if stored.HandoffMinSimilarity.Valid {
+		p.handoffs.minSimilarity = stored.HandoffMinSimilarity.Float64
+	}
+	if phrases := normalizePhrases(stored.HandoffPhrases); len(phrases) > 0 {
+		p.handoffs.phrases = phrases
+	}
+	return p, nil
+}
+
+// modelFor returns the completer and context budget for a chat model,
+// creating them the first time a persona asks for it. An empty name is the
+// configured AI_CHAT_MODEL.
+func (b *Bot) modelFor(name string) (botModel, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == b.completer.Model() {
+		return botModel{completer: b.completer, contexts: b.contexts}, nil
+	}
+
+	b.modelsMu.Lock()
+	defer b.modelsMu.Unlock()
+	if m, ok := b.models[name]; ok {
+		return m, nil
+	}
+	config := b.config
+	config.AIChatModel = name
+	completer, err := NewCompletionProvider(config)
+	if err != nil {
+		return botModel{}, err
+	}
+	m := botModel{completer: completer, contexts: NewContextBuilder(config, completer.Model())}
+	b.models[name] = m
+	return m, nil
+}
+
+// chatPersona returns the persona answering chat. A chat keeps the persona
+// it was first matched to, so it does not change voice halfway through when
+// an admin moves it to another department; admins can reassign it instead.
+func (b *Bot) chatPersona(ctx context.Context, chat db.Chat, department string) persona {
+	var stored db.BotPersona
+	if chat.PersonaSlug.Valid {
+		var err error
+		stored, err = b.store.Querier.GetBotPersona(ctx, chat.PersonaSlug.String)
+		if err != nil {
+			fmt.Printf("Cannot load persona %q for chat %s: %v\n", chat.PersonaSlug.String, chat.ChatExternalID, err)
+			return b.builtinPersona()
+		}
+	} else {
+		personas, err := b.store.Querier.ListBotPersonas(ctx)
+		if err != nil {
+			fmt.Printf("Cannot list personas: %v\n", err)
+			return b.builtinPersona()
+		}
+		var ok bool
+		stored, ok = MatchPersona(personas, chat.EntryPoint.String, chat.Label, department)
+		if !ok {
+			return b.builtinPersona()
+		}
+		_, err = b.store.Querier.SetChatPersona(ctx, db.SetChatPersonaParams{
+			ChatExternalID: chat.ChatExternalID,
+			PersonaSlug:    pgtype.Text{String: stored.Slug, Valid: true},
+		})
+		if err != nil {
+			fmt.Printf("Cannot assign persona %s to chat %s: %v\n", stored.Slug, chat.ChatExternalID, err)
+		}
+	}
+
+	p, err := b.newPersona(stored)
+	if err != nil {
+		fmt.Printf("Cannot set up persona %s, using the default settings: %v\n", stored.Slug, err)
+		return b.builtinPersona()
+	}
+	return p
+}
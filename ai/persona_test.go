@@ -0,0 +1,44 @@
+package ai
+
+import (
+	"testing"
+
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+func TestMatchPersona(t *testing.T) {
+	// In slug order, as ListBotPersonas returns them.
+	personas := []db.BotPersona{
+		{Slug: "billing", LabelKeywords: []string{"invoice", "صورتحساب"}, Departments: []string{"billing"}},
+		{Slug: "sales", EntryPoints: []string{"pricing-page"}, LabelKeywords: []string{"price"}, Departments: []string{"sales", "billing"}},
+		{Slug: "tech", EntryPoints: []string{"app"}, LabelKeywords: []string{"router", "error"}, Departments: []string{"tech"}},
+	}
+	tests := []struct {
+		name       string
+		entryPoint string
+		label      string
+		department string
+		want       string
+	}{
+		{name: "entry point", entryPoint: "pricing-page", want: "sales"},
+		{name: "entry point ignores case and blanks", entryPoint: " Pricing-Page ", want: "sales"},
+		{name: "entry point beats label and department", entryPoint: "app", label: "invoice", department: "billing", want: "tech"},
+		{name: "unknown entry point falls through", entryPoint: "widget", label: "Router is down", want: "tech"},
+		{name: "label keyword", label: "Question about my INVOICE!", want: "billing"},
+		{name: "Persian label keyword", label: "مشکل صورتحساب", want: "billing"},
+		{name: "label keyword beats department", label: "price list", department: "tech", want: "sales"},
+		{name: "keyword must be a whole word", label: "errors everywhere", want: ""},
+		{name: "first persona in slug order wins", label: "invoice error", want: "billing"},
+		{name: "department", label: "help", department: "tech", want: "tech"},
+		{name: "department shared by two personas", department: "billing", want: "billing"},
+		{name: "nothing applies", label: "hello", department: "hr", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MatchPersona(personas, tt.entryPoint, tt.label, tt.department)
+			if ok != (tt.want != "") || got.Slug != tt.want {
+				t.Errorf("MatchPersona() = %q, %v, want %q", got.Slug, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+// PgVectorStore searches the chunks table through pgvector. The column is
+// declared without a dimension so that models can change, which is why every
+// query casts to vector(dims): that is what lets Postgres use the partial
+// HNSW index built for this dimension.
+type PgVectorStore struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	dims    int
+}
+
+func NewPgVectorStore(pool *pgxpool.Pool, dims int) *PgVectorStore {
+	return &PgVectorStore{
+		pool:    pool,
+		queries: db.New(pool),
+		dims:    dims,
+	}
+}
+
+func (s *PgVectorStore) EnsureIndex(ctx context.Context) error {
+	stmt := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_chunks_embedding_hnsw_%[1]d
+ON chunks USING hnsw ((embedding_vector::vector(%[1]d)) vector_cosine_ops)
+WHERE vector_dims(embedding_vector) = %[1]d`, s.dims)
+	if _, err := s.pool.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("cannot create vector index: %w", err)
+	}
+	return nil
+}
+
+func (s *PgVectorStore) Add(ctx context.Context, chunks []Chunk) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := s.queries.WithTx(tx)
+	for i, c := range chunks {
+		if len(c.Embedding) != s.dims {
+			return fmt.Errorf("chunk embedding has %d dimensions, store expects %d", len(c.Embedding), s.dims)
+		}
+		embJSON, err := json.Marshal(c.Embedding)
+		if err != nil {
+			return err
+		}
+		row, err := q.CreateChunk(ctx, db.CreateChunkParams{
+			SourceID:        pgtype.UUID{Bytes: c.SourceID, Valid: c.SourceID != uuid.Nil},
+			SourcePath:      pgtype.Text{String: c.SourcePath, Valid: c.SourcePath != ""},
+			SourceFilename:  pgtype.Text{String: c.SourceFilename, Valid: c.SourceFilename != ""},
+			SourceMime:      pgtype.Text{String: c.SourceMime, Valid: c.SourceMime != ""},
+			SourcePage:      pgtype.Int4{Int32: int32(c.SourcePage), Valid: c.SourcePage > 0},
+			Department:      pgtype.Text{String: c.Department, Valid: c.Department != ""},
+			Language:        pgtype.Text{String: c.Language, Valid: c.Language != ""},
+			Text:            c.Text,
+			EmbeddingVector: vectorLiteral(c.Embedding),
+			EmbeddingJson:   embJSON,
+			ChunkHash:       pgtype.Text{String: c.Hash, Valid: c.Hash != ""},
+			CreatedBy:       pgtype.UUID{Bytes: c.CreatedBy, Valid: c.CreatedBy != uuid.Nil},
+			HeadingPath:     pgtype.Text{String: c.HeadingPath, Valid: c.HeadingPath != ""},
+			ChunkExternalID: pgtype.UUID{Bytes: c.ID, Valid: c.ID != uuid.Nil},
+		})
+		if err != nil {
+			return err
+		}
+		chunks[i].ID = row.ChunkExternalID
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *PgVectorStore) SourceChunks(ctx context.Context, source SourceRef) ([]ChunkRef, error) {
+	var out []ChunkRef
+	if source.ID != uuid.Nil {
+		rows, err := s.queries.ListChunkHashesBySource(ctx, pgtype.UUID{Bytes: source.ID, Valid: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out = append(out, ChunkRef{ID: r.ChunkExternalID, Hash: r.ChunkHash.String, Status: r.Status.String})
+		}
+		return out, nil
+	}
+	rows, err := s.queries.ListChunkHashesBySourcePath(ctx, pgtype.Text{String: source.Path, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out = append(out, ChunkRef{ID: r.ChunkExternalID, Hash: r.ChunkHash.String, Status: r.Status.String})
+	}
+	return out, nil
+}
+
+func (s *PgVectorStore) SetStatus(ctx context.Context, ids []uuid.UUID, status string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := s.queries.WithTx(tx)
+	for _, id := range ids {
+		err := q.UpdateChunkStatus(ctx, db.UpdateChunkStatusParams{
+			ChunkExternalID: id,
+			Status:          pgtype.Text{String: status, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *PgVectorStore) Search(ctx context.Context, embedding []float64, topK int, filter SearchFilter) ([]ScoredChunk, error) {
+	if len(embedding) != s.dims {
+		return nil, fmt.Errorf("query embedding has %d dimensions, store expects %d", len(embedding), s.dims)
+	}
+	query := fmt.Sprintf(`SELECT %[2]s,
+       1 - (embedding_vector::vector(%[1]d) <=> $1::vector(%[1]d)) AS score
+FROM chunks
+LEFT JOIN chunk_feedback USING (chunk_external_id)
+WHERE vector_dims(embedding_vector) = %[1]d
+  AND COALESCE(status, 'ready') = 'ready'
+  AND ($3 = '' OR department = $3 OR department IS NULL)
+  AND ($4 = '' OR language = $4 OR language IS NULL)
+  AND ((cardinality($5::text[]) = 0 AND cardinality($6::text[]) = 0)
+       OR (cardinality($5::text[]) > 0 AND (department IS NULL OR department = ANY($5::text[])))
+       OR source_id::text = ANY($6::text[]))
+ORDER BY embedding_vector::vector(%[1]d) <=> $1::vector(%[1]d)
+LIMIT $2`, s.dims, scoredChunkColumns)
+
+	departments, sources := filter.scopeArgs()
+	rows, err := s.pool.Query(ctx, query, vectorLiteral(embedding), topK, filter.Department, filter.Language, departments, sources)
+	if err != nil {
+		return nil, err
+	}
+	return scanScoredChunks(rows)
+}
+
+// SearchText matches any of the query terms against text_tsv, which the
+// chunks trigger fills with the 'simple' configuration so Persian words are
+// kept as they are.
+func (s *PgVectorStore) SearchText(ctx context.Context, query string, topK int, filter SearchFilter) ([]ScoredChunk, error) {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	stmt := fmt.Sprintf(`SELECT %s, ts_rank_cd(text_tsv, q) AS score
+FROM chunks
+LEFT JOIN chunk_feedback USING (chunk_external_id),
+     to_tsquery('simple', $1) AS q
+WHERE text_tsv @@ q
+  AND COALESCE(status, 'ready') = 'ready'
+  AND ($3 = '' OR department = $3 OR department IS NULL)
+  AND ($4 = '' OR language = $4 OR language IS NULL)
+  AND ((cardinality($5::text[]) = 0 AND cardinality($6::text[]) = 0)
+       OR (cardinality($5::text[]) > 0 AND (department IS NULL OR department = ANY($5::text[])))
+       OR source_id::text = ANY($6::text[]))
+ORDER BY score DESC
+LIMIT $2`, scoredChunkColumns)
+
+	departments, sources := filter.scopeArgs()
+	rows, err := s.pool.Query(ctx, stmt, strings.Join(terms, " | "), topK, filter.Department, filter.Language, departments, sources)
+	if err != nil {
+		return nil, err
+	}
+	return scanScoredChunks(rows)
+}
+
+const scoredChunkColumns = `chunk_external_id, text,
+       source_id, COALESCE(source_path, ''), COALESCE(source_filename, ''), COALESCE(source_mime, ''),
+       COALESCE(source_page, 0), COALESCE(heading_path, ''), COALESCE(department, ''), COALESCE(language, ''),
+       COALESCE(feedback_score, 0), COALESCE(feedback_weight, 0)`
+
+func scanScoredChunks(rows pgx.Rows) ([]ScoredChunk, error) {
+	defer rows.Close()
+
+	var out []ScoredChunk
+	for rows.Next() {
+		var sc ScoredChunk
+		var sourceID pgtype.UUID
+		var page int32
+		var feedbackScore, feedbackWeight int64
+		if err := rows.Scan(
+			&sc.ID,
+			&sc.Text,
+			&sourceID,
+			&sc.SourcePath,
+			&sc.SourceFilename,
+			&sc.SourceMime,
+			&page,
+			&sc.HeadingPath,
+			&sc.Department,
+			&sc.Language,
+			&feedbackScore,
+			&feedbackWeight,
+			&sc.Score,
+		); err != nil {
+			return nil, err
+		}
+		if sourceID.Valid {
+			sc.SourceID = sourceID.Bytes
+		}
+		sc.SourcePage = int(page)
+		sc.Helpfulness = helpfulness(feedbackScore, feedbackWeight)
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
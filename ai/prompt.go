@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+// DefaultPromptName is the template the bot answers with.
+const DefaultPromptName = "default"
+
+const (
+	defaultSystemPrompt  = `You are {{if .BotName}}{{.BotName}}, {{end}}a helpful assistant{{if .CompanyName}} for {{.CompanyName}}{{end}}. Answer in {{.Language}}.`
+	defaultContextPrompt = "Use the following context to answer the user's question. If unsure, say you don't know.\n\n{{.Context}}User question:\n{{.Question}}"
+)
+
+// PromptTemplate is one version of the bot's prompt. System becomes the
+// system message and Context the final user message. Both are Go templates
+// over PromptVars, e.g. "Answer in {{.Language}}".
+type PromptTemplate struct {
+	ID      uuid.UUID
+	Name    string
+	Version int32
+	System  string
+	Context string
+}
+
+// PromptVars are the variables a template can use. BotName is the display
+// name of the persona answering, empty without one. Context holds the
+// retrieved chunks already labelled "Context 1 (source):", the numbering
+// citations refer to. History is the earlier turns as a transcript; a
+// template that uses it gets them inline instead of as separate messages.
+type PromptVars struct {
+	CompanyName  string
+	BotName      string
+	Language     string
+	LanguageCode string
+	Context      string
+	History      string
+	Question     string
+	User         PromptUser
+}
+
+type PromptUser struct {
+	Name  string
+	Email string
+	Role  string
+}
+
+// BuiltinPromptTemplate is what the bot uses until an admin activates a
+// template of their own. Its ID is uuid.Nil, so messages answered with it
+// record no template.
+func BuiltinPromptTemplate() PromptTemplate {
+	return PromptTemplate{Name: DefaultPromptName, System: defaultSystemPrompt, Context: defaultContextPrompt}
+}
+
+func PromptTemplateFromDB(t db.PromptTemplate) PromptTemplate {
+	return PromptTemplate{
+		ID:      t.TemplateExternalID,
+		Name:    t.Name,
+		Version: t.Version,
+		System:  t.SystemPrompt,
+		Context: t.ContextPrompt,
+	}
+}
+
+// ValidatePromptTemplate parses both parts and renders them once with sample
+// values, so a typo such as {{.Langauge}} is caught when the template is
+// saved rather than when a user is waiting for an answer.
+func ValidatePromptTemplate(systemPrompt, contextPrompt string) error {
+	t := PromptTemplate{System: systemPrompt, Context: contextPrompt}
+	_, err := t.Render(PromptVars{
+		CompanyName:  "Example Co",
+		BotName:      "Sample Bot",
+		Language:     "English",
+		LanguageCode: LanguageEnglish,
+		Context:      "Context 1 (guide.pdf, page 1):\nSample text.\n\n",
+		History:      "user: hi\nassistant: hello\n",
+		Question:     "How do I reset my password?",
+		User:         PromptUser{Name: "Sample User", Email: "user@example.com", Role: "user"},
+	}, nil)
+	return err
+}
+
+// Render lays out the prompt as system, earlier turns, then the context
+// message with the current question.
+func (t PromptTemplate) Render(vars PromptVars, history []ChatMessage) ([]ChatMessage, error) {
+	system, err := renderPart("system_prompt", t.System, vars)
+	if err != nil {
+		return nil, err
+	}
+	user, err := renderPart("context_prompt", t.Context, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]ChatMessage, 0, len(history)+2)
+	messages = append(messages, ChatMessage{Role: "system", Content: system})
+	if !t.inlinesHistory() {
+		messages = append(messages, history...)
+	}
+	messages = append(messages, ChatMessage{Role: "user", Content: user})
+	return messages, nil
+}
+
+func (t PromptTemplate) inlinesHistory() bool {
+	return strings.Contains(t.System, ".History") || strings.Contains(t.Context, ".History")
+}
+
+func renderPart(name, text string, vars PromptVars) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// promptVars fills in everything but the user profile. The context may be in
+// another language than the answer; the template asks for lang anyway.
+func promptVars(company, question string, chunks []Chunk, history []ChatMessage, lang string) PromptVars {
+	return PromptVars{
+		CompanyName:  company,
+		Language:     languageName(lang),
+		LanguageCode: lang,
+		Context:      contextText(chunks),
+		History:      transcript(history),
+		Question:     question,
+	}
+}
+
+// ActivePrompt returns the live version of the named template, or the
+// built-in one when none is active or the lookup fails.
+func ActivePrompt(ctx context.Context, store *db.SQLStore, name string) PromptTemplate {
+	t, err := store.Querier.GetActivePromptTemplate(ctx, name)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			fmt.Printf("Cannot load prompt template %q, using the built-in one: %v\n", name, err)
+		}
+		return BuiltinPromptTemplate()
+	}
+	return PromptTemplateFromDB(t)
+}
+
+func promptUser(ctx context.Context, store *db.SQLStore, userID uuid.UUID) PromptUser {
+	u, err := store.Querier.GetUser(ctx, userID)
+	if err != nil {
+		return PromptUser{}
+	}
+	return PromptUser{
+		Name:  strings.TrimSpace(u.FirstName + " " + u.LastName),
+		Email: u.Email,
+		Role:  u.Role,
+	}
+}
+
+// prompt renders the persona's live template for one answer, packed into
+// the persona model's context budget, and returns the ID of the version used.
+// A persona whose template has no live version uses the default one. A
+// template that fails to render falls back to the built-in one rather than
+// leaving the user without an answer.
+func (b *Bot) prompt(ctx context.Context, chat db.Chat, p persona, question string, chunks []Chunk, history []ChatMessage, lang string) (PackedContext, uuid.UUID) {
+	vars := promptVars(b.config.CompanyName, question, nil, nil, lang)
+	vars.BotName = p.name
+	vars.User = promptUser(ctx, b.store, chat.UserExternalID)
+
+	t := ActivePrompt(ctx, b.store, p.promptName)
+	if t.ID == uuid.Nil && p.promptName != DefaultPromptName {
+		t = ActivePrompt(ctx, b.store, DefaultPromptName)
+	}
+	packed, err := p.model.contexts.Pack(t, vars, chunks, history)
+	if err != nil {
+		fmt.Printf("Prompt template %s v%d failed, using the built-in one: %v\n", t.Name, t.Version, err)
+		t = BuiltinPromptTemplate()
+		packed, _ = p.model.contexts.Pack(t, vars, chunks, history)
+	}
+	fmt.Printf("Context for chat %s: %s\n", chat.ChatExternalID, packed)
+	return packed, t.ID
+}
@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+// PromptPreviewer shows admins what a template turns into for a sample
+// question: the same retrieval and rendering the bot does, and optionally
+// the answer the model gives, without anything being stored.
+type PromptPreviewer struct {
+	config    util.Config
+	store     *db.SQLStore
+	retriever *Retriever
+	completer CompletionProvider
+	languages languagePolicy
+	contexts  *ContextBuilder
+}
+
+func NewPromptPreviewer(config util.Config, store *db.SQLStore, vectorStore VectorStore, embedder EmbeddingProvider) (*PromptPreviewer, error) {
+	completer, err := NewCompletionProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create completion provider: %w", err)
+	}
+	return &PromptPreviewer{
+		config:    config,
+		store:     store,
+		retriever: NewRetriever(config, embedder, vectorStore),
+		completer: completer,
+		languages: newLanguagePolicy(config),
+		contexts:  NewContextBuilder(config, completer.Model()),
+	}, nil
+}
+
+type PromptPreviewRequest struct {
+	Template   PromptTemplate
+	Question   string
+	Language   string
+	Department string
+	// UserExternalID is whose profile fills {{.User}}; usually the admin
+	// asking for the preview.
+	UserExternalID uuid.UUID
+	Generate       bool
+}
+
+type PromptPreview struct {
+	Messages []ChatMessage
+	Sources  []Chunk
+	Answer   string
+	Packing  PackedContext
+}
+
+func (p *PromptPreviewer) Preview(ctx context.Context, req PromptPreviewRequest) (PromptPreview, error) {
+	var preview PromptPreview
+	lang := req.Language
+	if lang == "" {
+		lang = p.languages.replyLanguage(req.Question, nil)
+	}
+
+	found, err := retrievePreferringLanguage(ctx, p.retriever, req.Question, p.contexts.TopK(), SearchFilter{
+		Department: req.Department,
+		Language:   lang,
+	})
+	if err != nil {
+		return preview, fmt.Errorf("retrieval failed: %w", err)
+	}
+
+	vars := promptVars(p.config.CompanyName, req.Question, nil, nil, lang)
+	vars.User = promptUser(ctx, p.store, req.UserExternalID)
+	preview.Packing, err = p.contexts.Pack(req.Template, vars, found.Chunks, nil)
+	if err != nil {
+		return preview, err
+	}
+	preview.Messages = preview.Packing.Messages
+	preview.Sources = preview.Packing.Chunks
+
+	if req.Generate {
+		resp, err := p.completer.Complete(ctx, CompletionRequest{
+			Messages:    preview.Messages,
+			Temperature: p.config.AITemperature,
+			MaxTokens:   p.contexts.AnswerTokens(),
+		})
+		if err != nil {
+			return preview, fmt.Errorf("completion failed: %w", err)
+		}
+		preview.Answer = resp.Content
+	}
+	return preview, nil
+}
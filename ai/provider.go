@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+	ProviderFake   = "fake"
+)
+
+// ChatMessage is one turn of the conversation sent to the model. An
+// assistant turn that asked for tools carries ToolCalls, and each result
+// goes back as a "tool" turn naming the call it answers.
+type ChatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	ToolName   string     `json:"tool_name,omitempty"`
+}
+
+// ToolSpec describes a tool the model may call. Parameters is a JSON schema
+// for the arguments object.
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+}
+
+// ToolCall is the model asking for one tool. Arguments is the JSON object
+// the model wrote; nothing has checked it yet.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// CompletionRequest is one call to the model. MaxTokens caps the answer;
+// 0 leaves it to the backend. Without Tools the model can only answer in
+// text.
+type CompletionRequest struct {
+	Messages    []ChatMessage
+	Temperature float64
+	MaxTokens   int
+	Tools       []ToolSpec
+}
+
+// CompletionResponse holds the answer, or the tools the model wants run
+// before it answers. Content may be set alongside ToolCalls.
+type CompletionResponse struct {
+	Content   string
+	ToolCalls []ToolCall
+}
+
+// CompletionProvider turns a list of chat messages into the assistant's answer.
+// Stream calls onDelta for every piece of text as it arrives and returns the
+// full answer once the backend is done.
+type CompletionProvider interface {
+	Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error)
+	Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (CompletionResponse, error)
+	Model() string
+}
+
+// EmbeddingProvider returns one vector per input text, in input order.
+type EmbeddingProvider interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+	Model() string
+}
+
+func NewCompletionProvider(config util.Config) (CompletionProvider, error) {
+	switch providerName(config.AIProvider) {
+	case ProviderOpenAI:
+		return newOpenAICompletion(config), nil
+	case ProviderOllama:
+		return newOllamaCompletion(config), nil
+	case ProviderFake:
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", config.AIProvider)
+	}
+}
+
+func NewEmbeddingProvider(config util.Config) (EmbeddingProvider, error) {
+	name := config.AIEmbeddingProvider
+	if name == "" {
+		name = config.AIProvider
+	}
+	switch providerName(name) {
+	case ProviderOpenAI:
+		return newOpenAIEmbedding(config), nil
+	case ProviderOllama:
+		return newOllamaEmbedding(config), nil
+	case ProviderFake:
+		return &FakeProvider{Dimensions: embeddingDimensions(config)}, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", name)
+	}
+}
+
+func providerName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ProviderOpenAI
+	}
+	return name
+}
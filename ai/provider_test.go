@@ -0,0 +1,248 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+func TestFakeProviderEmbed(t *testing.T) {
+	p := NewFakeProvider()
+	embs, err := p.Embed(context.Background(), []string{
+		"How do I reset my password?",
+		"how do I RESET my password",
+		"Reset your password from the login page.",
+		"Orders ship from the Tehran warehouse.",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range embs[:4] {
+		var norm float64
+		for _, v := range e {
+			norm += v * v
+		}
+		if len(e) != defaultEmbeddingDimensions || math.Abs(norm-1) > 1e-9 {
+			t.Errorf("embedding %d has %d dimensions and norm %v, want %d and 1", i, len(e), norm, defaultEmbeddingDimensions)
+		}
+	}
+	if !reflect.DeepEqual(embs[0], embs[1]) {
+		t.Error("case and punctuation changed the embedding")
+	}
+	if related, unrelated := cosineSim(embs[0], embs[2]), cosineSim(embs[0], embs[3]); related <= unrelated {
+		t.Errorf("related texts score %v, unrelated %v", related, unrelated)
+	}
+	if want := make([]float64, defaultEmbeddingDimensions); !reflect.DeepEqual(embs[4], want) {
+		t.Errorf("empty text gave %v, want a zero vector", embs[4])
+	}
+
+	// Without AI_EMBEDDING_DIMENSIONS the fake must still fit the vector store.
+	unset, err := NewEmbeddingProvider(util.Config{AIProvider: ProviderFake})
+	if err != nil {
+		t.Fatal(err)
+	}
+	embs, err = unset.Embed(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := embeddingDimensions(util.Config{}); len(embs[0]) != want {
+		t.Errorf("unset AI_EMBEDDING_DIMENSIONS gave %d dimensions, the vector store expects %d", len(embs[0]), want)
+	}
+
+	small, err := NewEmbeddingProvider(util.Config{AIProvider: ProviderFake, AIEmbeddingDims: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	embs, err = small.Embed(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embs[0]) != 8 {
+		t.Errorf("AI_EMBEDDING_DIMENSIONS 8 gave %d dimensions", len(embs[0]))
+	}
+}
+
+func TestFakeProviderCompletion(t *testing.T) {
+	p := NewFakeProvider()
+	req := CompletionRequest{Messages: []ChatMessage{
+		{Role: "system", Content: "Be nice."},
+		{Role: "user", Content: "first question"},
+		{Role: "assistant", Content: "first answer"},
+		{Role: "user", Content: "How do I reset my password?"},
+	}}
+	var deltas []string
+	resp, err := p.Stream(context.Background(), req, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "How do I reset my password?" {
+		t.Errorf("answer %q, want the last user message", resp.Content)
+	}
+	if len(deltas) != 6 || strings.Join(deltas, "") != resp.Content {
+		t.Errorf("deltas %q do not add up to %q word by word", deltas, resp.Content)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Complete(ctx, req); err == nil {
+		t.Error("Complete() on a cancelled context succeeded")
+	}
+}
+
+func TestOpenAIEmbed(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    [][]float64
+		wantErr string
+	}{
+		{
+			name: "in order",
+			body: `{"data":[{"index":0,"embedding":[1,0]},{"index":1,"embedding":[0,1]}]}`,
+			want: [][]float64{{1, 0}, {0, 1}},
+		},
+		{
+			name: "placed by index",
+			body: `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`,
+			want: [][]float64{{1, 0}, {0, 1}},
+		},
+		{
+			name:    "input left without a vector",
+			body:    `{"data":[{"index":1,"embedding":[0,1]}]}`,
+			wantErr: "no embedding for input 0 of 2",
+		},
+		{
+			name:    "empty vector",
+			body:    `{"data":[{"index":0,"embedding":[1,0]},{"index":1,"embedding":[]}]}`,
+			wantErr: "no embedding for input 1 of 2",
+		},
+		{
+			name:    "index twice",
+			body:    `{"data":[{"index":0,"embedding":[1,0]},{"index":0,"embedding":[0,1]}]}`,
+			wantErr: "index 0 twice",
+		},
+		{
+			name:    "index out of range",
+			body:    `{"data":[{"index":0,"embedding":[1,0]},{"index":2,"embedding":[0,1]}]}`,
+			wantErr: "out of range index 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Model string   `json:"model"`
+					Input []string `json:"input"`
+				}
+				if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+					t.Errorf("request to %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "embed-test" || len(req.Input) != 2 {
+					t.Errorf("request body %+v, %v", req, err)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			p := newOpenAIEmbedding(util.Config{AIBaseURL: srv.URL + "/", AIAPIKey: "secret", AIEmbeddingModel: "embed-test"})
+			got, err := p.Embed(context.Background(), []string{"a", "b"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Embed() error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Embed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIStreamToolCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunks    []string
+		want      CompletionResponse
+		wantDelta string
+		wantErr   string
+	}{
+		{
+			name: "text",
+			chunks: []string{
+				`{"choices":[{"delta":{"content":"Hello "}}]}`,
+				`{"choices":[{"delta":{"content":"there"}}]}`,
+			},
+			want:      CompletionResponse{Content: "Hello there"},
+			wantDelta: "Hello there",
+		},
+		{
+			name: "tool calls in fragments",
+			chunks: []string{
+				`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"close_","arguments":""}}]}}]}`,
+				`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"name":"chat","arguments":"{\"reason\":"}}]}}]}`,
+				`{"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_2","function":{"name":"get_account_status"}}]}}]}`,
+				`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"done\"}"}}]}}]}`,
+			},
+			want: CompletionResponse{ToolCalls: []ToolCall{
+				{ID: "call_1", Name: "close_chat", Arguments: json.RawMessage(`{"reason":"done"}`)},
+				{ID: "call_2", Name: "get_account_status", Arguments: json.RawMessage(`{}`)},
+			}},
+		},
+		{
+			name:    "negative tool call index",
+			chunks:  []string{`{"choices":[{"delta":{"tool_calls":[{"index":-1,"function":{"name":"close_chat"}}]}}]}`},
+			wantErr: "out of range tool call index -1",
+		},
+		{
+			name:    "huge tool call index",
+			chunks:  []string{`{"choices":[{"delta":{"tool_calls":[{"index":1000000000,"function":{"name":"close_chat"}}]}}]}`},
+			wantErr: "out of range tool call index 1000000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for _, chunk := range tt.chunks {
+					fmt.Fprintf(w, "data: %s\n\n", chunk)
+				}
+				fmt.Fprint(w, "data: [DONE]\n\n")
+			}))
+			defer srv.Close()
+
+			p := newOpenAICompletion(util.Config{AIBaseURL: srv.URL})
+			var deltas strings.Builder
+			got, err := p.Stream(context.Background(), CompletionRequest{}, func(delta string) error {
+				deltas.WriteString(delta)
+				return nil
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Stream() error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || deltas.String() != tt.wantDelta {
+				t.Errorf("Stream() = %+v with deltas %q, want %+v with %q", got, deltas.String(), tt.want, tt.wantDelta)
+			}
+		})
+	}
+}
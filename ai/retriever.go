@@ -0,0 +1,187 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultRRFK         = 60
+	minCandidatePool    = 20
+	candidateMultiplier = 4
+
+	// feedbackPrior is how many points of reactions a chunk needs before its
+	// helpfulness is taken at half its face value, so one stray thumbs down
+	// does not bury a chunk.
+	feedbackPrior = 5
+)
+
+// Retriever runs a full-text search and a vector search side by side and
+// merges them with weighted reciprocal rank fusion, so exact terms that the
+// embedding misses (product codes, names) still surface.
+type Retriever struct {
+	embedder     EmbeddingProvider
+	store        VectorStore
+	vectorWeight float64
+	textWeight   float64
+	rrfK         int
+	// feedback scales how far reactions move a chunk's fused score, by up
+	// to this share either way; 0 turns it off.
+	feedback float64
+}
+
+func NewRetriever(config util.Config, embedder EmbeddingProvider, store VectorStore) *Retriever {
+	r := &Retriever{
+		embedder:     embedder,
+		store:        store,
+		vectorWeight: config.RetrievalVectorWeight,
+		textWeight:   config.RetrievalTextWeight,
+		rrfK:         config.RetrievalRRFK,
+		feedback:     config.RetrievalFeedbackWeight,
+	}
+	if r.vectorWeight <= 0 && r.textWeight <= 0 {
+		r.vectorWeight, r.textWeight = 1, 1
+	}
+	if r.rrfK <= 0 {
+		r.rrfK = defaultRRFK
+	}
+	return r
+}
+
+// Retrieval is what a search found. TopSimilarity is the cosine similarity
+// of the best vector hit; fused ranks say nothing about how relevant the
+// chunks are, so callers judging confidence look at it instead.
+// HasSimilarity is false when the vector search did not run.
+type Retrieval struct {
+	Chunks        []Chunk
+	TopSimilarity float64
+	HasSimilarity bool
+}
+
+func (r *Retriever) Retrieve(ctx context.Context, query string, topK int, filter SearchFilter) (Retrieval, error) {
+	if topK <= 0 {
+		return Retrieval{}, nil
+	}
+	candidates := topK * candidateMultiplier
+	if candidates < minCandidatePool {
+		candidates = minCandidatePool
+	}
+
+	var (
+		wg                   sync.WaitGroup
+		vectorHits, textHits []ScoredChunk
+		vectorErr, textErr   error
+	)
+	if r.vectorWeight > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vectorHits, vectorErr = r.vectorSearch(ctx, query, candidates, filter)
+		}()
+	}
+	if r.textWeight > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			textHits, textErr = r.store.SearchText(ctx, query, candidates, filter)
+		}()
+	}
+	wg.Wait()
+
+	// One side failing should not cost the user an answer.
+	if vectorErr != nil && textErr != nil {
+		return Retrieval{}, fmt.Errorf("vector search: %v; text search: %w", vectorErr, textErr)
+	}
+	if vectorErr != nil {
+		fmt.Printf("Vector search failed, using full-text results only: %v\n", vectorErr)
+	}
+	if textErr != nil {
+		fmt.Printf("Full-text search failed, using vector results only: %v\n", textErr)
+	}
+
+	fused := fuseRanks(r.rrfK, rankedList{r.vectorWeight, vectorHits}, rankedList{r.textWeight, textHits})
+	applyFeedback(fused, r.feedback)
+	if len(fused) > topK {
+		fused = fused[:topK]
+	}
+	out := Retrieval{Chunks: make([]Chunk, 0, len(fused))}
+	for _, sc := range fused {
+		out.Chunks = append(out.Chunks, sc.Chunk)
+	}
+	if r.vectorWeight > 0 && vectorErr == nil {
+		out.HasSimilarity = true
+		if len(vectorHits) > 0 {
+			out.TopSimilarity = vectorHits[0].Score
+		}
+	}
+	return out, nil
+}
+
+func (r *Retriever) vectorSearch(ctx context.Context, query string, topK int, filter SearchFilter) ([]ScoredChunk, error) {
+	embs, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(embs) == 0 {
+		return nil, fmt.Errorf("no embedding returned for query")
+	}
+	return r.store.Search(ctx, embs[0], topK, filter)
+}
+
+type rankedList struct {
+	weight float64
+	hits   []ScoredChunk
+}
+
+// fuseRanks scores each chunk as the sum of weight/(k+rank) over the lists
+// it appears in. Only ranks matter, so cosine and ts_rank scores never have
+// to be put on the same scale.
+func fuseRanks(k int, lists ...rankedList) []ScoredChunk {
+	byID := make(map[uuid.UUID]*ScoredChunk)
+	var order []uuid.UUID
+	for _, l := range lists {
+		for rank, hit := range l.hits {
+			sc, ok := byID[hit.ID]
+			if !ok {
+				sc = &ScoredChunk{Chunk: hit.Chunk}
+				byID[hit.ID] = sc
+				order = append(order, hit.ID)
+			}
+			sc.Score += l.weight / float64(k+rank+1)
+		}
+	}
+
+	out := make([]ScoredChunk, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byID[id])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// applyFeedback scales each fused score by 1 + weight*Helpfulness and sorts
+// again, so chunks behind well received answers move up and chunks behind
+// downvoted ones move down, but only among comparably relevant chunks.
+func applyFeedback(fused []ScoredChunk, weight float64) {
+	if weight <= 0 {
+		return
+	}
+	for i := range fused {
+		fused[i].Score *= 1 + weight*fused[i].Helpfulness
+	}
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+}
+
+// helpfulness turns the totals of chunk_feedback into a score in (-1, 1):
+// the net reaction score over its size, damped by feedbackPrior.
+func helpfulness(score, weight int64) float64 {
+	if weight <= 0 {
+		return 0
+	}
+	return float64(score) / float64(weight+feedbackPrior)
+}
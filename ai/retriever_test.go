@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func scored(id uuid.UUID) ScoredChunk {
+	return ScoredChunk{Chunk: Chunk{ID: id}}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFuseRanks(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name       string
+		lists      []rankedList
+		wantOrder  []uuid.UUID
+		wantScores []float64
+	}{
+		{
+			name:       "single list keeps its order",
+			lists:      []rankedList{{1, []ScoredChunk{scored(a), scored(b)}}},
+			wantOrder:  []uuid.UUID{a, b},
+			wantScores: []float64{1.0 / 61, 1.0 / 62},
+		},
+		{
+			name: "chunk found by both searches wins",
+			lists: []rankedList{
+				{1, []ScoredChunk{scored(a), scored(b)}},
+				{1, []ScoredChunk{scored(c), scored(b)}},
+			},
+			wantOrder:  []uuid.UUID{b, a, c},
+			wantScores: []float64{2.0 / 62, 1.0 / 61, 1.0 / 61},
+		},
+		{
+			name: "weights favour one list",
+			lists: []rankedList{
+				{1, []ScoredChunk{scored(a)}},
+				{3, []ScoredChunk{scored(b), scored(c)}},
+			},
+			wantOrder:  []uuid.UUID{b, c, a},
+			wantScores: []float64{3.0 / 61, 3.0 / 62, 1.0 / 61},
+		},
+		{
+			name:  "empty lists",
+			lists: []rankedList{{1, nil}, {1, nil}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fuseRanks(defaultRRFK, tt.lists...)
+			if len(got) != len(tt.wantOrder) {
+				t.Fatalf("got %d chunks, want %d", len(got), len(tt.wantOrder))
+			}
+			for i, sc := range got {
+				if sc.ID != tt.wantOrder[i] || !approxEqual(sc.Score, tt.wantScores[i]) {
+					t.Errorf("rank %d: got %s with %v, want %s with %v", i+1, sc.ID, sc.Score, tt.wantOrder[i], tt.wantScores[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyFeedback(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	tests := []struct {
+		name       string
+		weight     float64
+		fused      []ScoredChunk
+		wantOrder  []uuid.UUID
+		wantScores []float64
+	}{
+		{
+			name:       "off",
+			weight:     0,
+			fused:      []ScoredChunk{{Chunk: Chunk{ID: a, Helpfulness: -1}, Score: 0.02}, {Chunk: Chunk{ID: b, Helpfulness: 1}, Score: 0.018}},
+			wantOrder:  []uuid.UUID{a, b},
+			wantScores: []float64{0.02, 0.018},
+		},
+		{
+			name:       "upvoted chunk overtakes a close downvoted one",
+			weight:     0.5,
+			fused:      []ScoredChunk{{Chunk: Chunk{ID: a, Helpfulness: -1}, Score: 0.02}, {Chunk: Chunk{ID: b, Helpfulness: 1}, Score: 0.018}},
+			wantOrder:  []uuid.UUID{b, a},
+			wantScores: []float64{0.027, 0.01},
+		},
+		{
+			name:       "feedback does not outweigh relevance",
+			weight:     0.2,
+			fused:      []ScoredChunk{{Chunk: Chunk{ID: a, Helpfulness: -1}, Score: 0.03}, {Chunk: Chunk{ID: b, Helpfulness: 1}, Score: 0.01}},
+			wantOrder:  []uuid.UUID{a, b},
+			wantScores: []float64{0.024, 0.012},
+		},
+		{
+			name:       "no feedback keeps the score",
+			weight:     0.5,
+			fused:      []ScoredChunk{{Chunk: Chunk{ID: a}, Score: 0.02}, {Chunk: Chunk{ID: b}, Score: 0.01}},
+			wantOrder:  []uuid.UUID{a, b},
+			wantScores: []float64{0.02, 0.01},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyFeedback(tt.fused, tt.weight)
+			for i, sc := range tt.fused {
+				if sc.ID != tt.wantOrder[i] || !approxEqual(sc.Score, tt.wantScores[i]) {
+					t.Errorf("rank %d: got %s with %v, want %s with %v", i+1, sc.ID, sc.Score, tt.wantOrder[i], tt.wantScores[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHelpfulness(t *testing.T) {
+	tests := []struct {
+		score, weight int64
+		want          float64
+	}{
+		{0, 0, 0},
+		{3, 0, 0},
+		{1, 1, 1.0 / 6},
+		{-1, 1, -1.0 / 6},
+		{10, 10, 10.0 / 15},
+		{-4, 6, -4.0 / 11},
+	}
+	for _, tt := range tests {
+		if got := helpfulness(tt.score, tt.weight); !approxEqual(got, tt.want) {
+			t.Errorf("helpfulness(%d, %d) = %v, want %v", tt.score, tt.weight, got, tt.want)
+		}
+	}
+}
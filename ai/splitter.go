@@ -0,0 +1,313 @@
+package ai
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	ChunkUnitChars  = "chars"
+	ChunkUnitTokens = "tokens"
+
+	defaultChunkChars  = 1000
+	defaultChunkTokens = 300
+
+	zwnj = '\u200c'
+)
+
+// Section is a piece of a split document together with the headings it sits
+// under, outermost first, joined with " > ".
+type Section struct {
+	HeadingPath string
+	Text        string
+}
+
+// textSplitter cuts text at the coarsest boundary that makes the pieces fit:
+// paragraphs, then lines, sentences, clauses and words. Only a single word
+// longer than a whole chunk is ever cut in the middle. Pieces are then packed
+// back together up to the chunk size, and each chunk starts with as many
+// trailing pieces of the previous one as fit in the overlap.
+type textSplitter struct {
+	size    int
+	overlap int
+	length  func(string) int
+}
+
+func newTextSplitter(config util.Config) *textSplitter {
+	s := &textSplitter{
+		size:    int(config.ChunkSize),
+		overlap: int(config.ChunkOverlap),
+		length:  runeCount,
+	}
+	if config.ChunkUnit == ChunkUnitTokens {
+		s.length = approxTokens
+		if s.size <= 0 {
+			s.size = defaultChunkTokens
+		}
+	} else if s.size <= 0 {
+		s.size = defaultChunkChars
+	}
+	if s.overlap < 0 {
+		s.overlap = 0
+	}
+	if s.overlap >= s.size {
+		s.overlap = s.size / 2
+	}
+	return s
+}
+
+// Split breaks text into chunks, tracking Markdown-style "#" headings (which
+// the DOCX, HTML and Markdown loaders all produce) so every chunk knows which
+// section it came from. Heading lines themselves are kept out of the chunk
+// text.
+func (s *textSplitter) Split(text string) []Section {
+	var out []Section
+	for _, sec := range splitHeadings(normalizeText(text)) {
+		for _, chunk := range s.merge(s.pieces(sec.Text, 0)) {
+			chunk = strings.TrimSpace(chunk)
+			if chunk == "" {
+				continue
+			}
+			out = append(out, Section{HeadingPath: sec.HeadingPath, Text: chunk})
+		}
+	}
+	return out
+}
+
+type boundary func(r []rune, i int) bool
+
+// separators are tried in order; a boundary at i means "cut after rune i".
+var separators = []boundary{
+	// paragraph: the last newline of a blank-line run
+	func(r []rune, i int) bool {
+		return r[i] == '\n' && i > 0 && r[i-1] == '\n' && (i+1 == len(r) || r[i+1] != '\n')
+	},
+	// line
+	func(r []rune, i int) bool { return r[i] == '\n' },
+	// sentence
+	func(r []rune, i int) bool { return isSentenceEnd(r[i]) && followedBySpace(r, i) },
+	// clause
+	func(r []rune, i int) bool { return isClauseEnd(r[i]) && followedBySpace(r, i) },
+	// word: the end of a whitespace run. ZWNJ is not whitespace, so Persian
+	// compounds joined with one stay whole.
+	func(r []rune, i int) bool {
+		return unicode.IsSpace(r[i]) && (i+1 == len(r) || !unicode.IsSpace(r[i+1]))
+	},
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', '؟', '۔', '…':
+		return true
+	}
+	return false
+}
+
+func isClauseEnd(r rune) bool {
+	switch r {
+	case ';', '؛', ',', '،', ':':
+		return true
+	}
+	return false
+}
+
+func followedBySpace(r []rune, i int) bool {
+	return i+1 == len(r) || unicode.IsSpace(r[i+1])
+}
+
+// pieces returns text cut into parts no longer than the chunk size, using
+// the separator at level and falling back to finer ones only where needed.
+// Concatenating the result gives back text unchanged.
+func (s *textSplitter) pieces(text string, level int) []string {
+	if s.length(text) <= s.size {
+		return []string{text}
+	}
+	if level >= len(separators) {
+		return s.cutWord(text)
+	}
+	parts := cutAfter(text, separators[level])
+	if len(parts) == 1 {
+		return s.pieces(text, level+1)
+	}
+	var out []string
+	for _, p := range parts {
+		out = append(out, s.pieces(p, level+1)...)
+	}
+	return out
+}
+
+func cutAfter(text string, at boundary) []string {
+	r := []rune(text)
+	var parts []string
+	start := 0
+	for i := range r {
+		if at(r, i) {
+			parts = append(parts, string(r[start:i+1]))
+			start = i + 1
+		}
+	}
+	if start < len(r) {
+		parts = append(parts, string(r[start:]))
+	}
+	return parts
+}
+
+// cutWord is the last resort for a word longer than a chunk. It takes the
+// longest prefix that fits and never cuts next to a ZWNJ, which would leave a
+// dangling half of a compound.
+func (s *textSplitter) cutWord(text string) []string {
+	r := []rune(text)
+	var out []string
+	for len(r) > 0 {
+		n := sort.Search(len(r), func(n int) bool { return s.length(string(r[:n+1])) > s.size })
+		if n == 0 {
+			n = 1
+		}
+		for n < len(r) && n > 1 && (r[n] == zwnj || r[n-1] == zwnj) {
+			n--
+		}
+		out = append(out, string(r[:n]))
+		r = r[n:]
+	}
+	return out
+}
+
+func (s *textSplitter) merge(pieces []string) []string {
+	var (
+		out    []string
+		cur    []string
+		curLen int
+	)
+	for _, p := range pieces {
+		n := s.length(p)
+		if len(cur) > 0 && curLen+n > s.size {
+			out = append(out, strings.Join(cur, ""))
+			for len(cur) > 0 && (curLen > s.overlap || curLen+n > s.size) {
+				curLen -= s.length(cur[0])
+				cur = cur[1:]
+			}
+		}
+		cur = append(cur, p)
+		curLen += n
+	}
+	if len(cur) > 0 {
+		out = append(out, strings.Join(cur, ""))
+	}
+	return out
+}
+
+type heading struct {
+	level int
+	title string
+}
+
+// splitHeadings groups lines under the "#" headings above them.
+func splitHeadings(text string) []Section {
+	var (
+		out   []Section
+		stack []heading
+		body  strings.Builder
+	)
+	path := func() string {
+		titles := make([]string, len(stack))
+		for i, h := range stack {
+			titles[i] = h.title
+		}
+		return strings.Join(titles, " > ")
+	}
+	flush := func() {
+		if strings.TrimSpace(body.String()) != "" {
+			out = append(out, Section{HeadingPath: path(), Text: body.String()})
+		}
+		body.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		level, title := parseHeading(line)
+		if level == 0 {
+			body.WriteString(line)
+			continue
+		}
+		flush()
+		for len(stack) > 0 && stack[len(stack)-1].level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, heading{level: level, title: title})
+	}
+	flush()
+	return out
+}
+
+func parseHeading(line string) (int, string) {
+	line = strings.TrimSpace(line)
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	title := strings.TrimSpace(strings.TrimRight(line[level:], "#"))
+	if title == "" {
+		return 0, ""
+	}
+	return level, title
+}
+
+// normalizeText drops invisible characters that only get in the way of
+// boundary detection (zero-width spaces, BOMs, direction marks), turns
+// non-breaking spaces into spaces and trims trailing blanks so blank lines
+// are recognised. ZWNJ is part of Persian spelling and is kept, except where
+// it sits next to a space and means nothing.
+func normalizeText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\ufeff', '\u200e', '\u200f', '\u00ad':
+			return -1
+		case '\u00a0', '\u202f':
+			return ' '
+		case '\r':
+			return '\n'
+		}
+		return r
+	}, text)
+	text = strings.NewReplacer(" \u200c", " ", "\u200c ", " ", "\u200c\n", "\n").Replace(text)
+
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRightFunc(l, unicode.IsSpace)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runeCount(s string) int {
+	return len([]rune(s))
+}
+
+// approxTokens estimates how many tokens a BPE tokenizer spends on s without
+// shipping a vocabulary: roughly four Latin characters per token, two for
+// Persian and other scripts, and one per punctuation mark. It only has to be
+// consistent, since it is used to size chunks, not to bill requests.
+func approxTokens(s string) int {
+	total := 0
+	for _, word := range strings.Fields(s) {
+		var latin, other int
+		for _, r := range word {
+			switch {
+			case r == zwnj:
+			case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+				latin++
+			case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+				other++
+			default:
+				total++
+			}
+		}
+		total += (latin+3)/4 + (other+1)/2
+	}
+	return total
+}
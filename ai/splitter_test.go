@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+func TestTextSplitterSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		overlap int64
+		text    string
+		want    []Section
+	}{
+		{
+			name: "heading paths",
+			size: 1000,
+			text: "# Guide\nIntro.\n\n## Install\nRun it.\n### Linux\nUse apt.\n# FAQ\nAsk us.\n",
+			want: []Section{
+				{HeadingPath: "Guide", Text: "Intro."},
+				{HeadingPath: "Guide > Install", Text: "Run it."},
+				{HeadingPath: "Guide > Install > Linux", Text: "Use apt."},
+				{HeadingPath: "FAQ", Text: "Ask us."},
+			},
+		},
+		{
+			name: "text before the first heading and hashes that are not headings",
+			size: 1000,
+			text: "Preface\n#hashtag\n# Body\nText",
+			want: []Section{
+				{Text: "Preface\n#hashtag"},
+				{HeadingPath: "Body", Text: "Text"},
+			},
+		},
+		{
+			name: "paragraphs before lines",
+			size: 12,
+			text: "first line\n\nsecond line\nthird",
+			want: []Section{{Text: "first line"}, {Text: "second line"}, {Text: "third"}},
+		},
+		{
+			name: "Persian question mark ends a sentence",
+			size: 15,
+			text: "سلام، خوبی؟ بله ممنونم.",
+			want: []Section{{Text: "سلام، خوبی؟"}, {Text: "بله ممنونم."}},
+		},
+		{
+			name: "Persian comma ends a clause",
+			size: 8,
+			text: "سلام، خوبی؟",
+			want: []Section{{Text: "سلام،"}, {Text: "خوبی؟"}},
+		},
+		{
+			name: "ZWNJ keeps a compound in one word",
+			size: 10,
+			text: "این کتاب\u200cها خوب است",
+			want: []Section{{Text: "این"}, {Text: "کتاب\u200cها"}, {Text: "خوب است"}},
+		},
+		{
+			name: "ZWNJ next to a space is dropped",
+			size: 1000,
+			text: "کتاب\u200c ها\u200b و دفتر",
+			want: []Section{{Text: "کتاب ها و دفتر"}},
+		},
+		{
+			name:    "overlap repeats trailing pieces",
+			size:    10,
+			overlap: 5,
+			text:    "aaaa bbbb cccc dddd",
+			want:    []Section{{Text: "aaaa bbbb"}, {Text: "bbbb cccc"}, {Text: "cccc dddd"}},
+		},
+		{
+			name: "no overlap",
+			size: 10,
+			text: "aaaa bbbb cccc dddd",
+			want: []Section{{Text: "aaaa bbbb"}, {Text: "cccc dddd"}},
+		},
+		{
+			name: "word longer than a chunk",
+			size: 4,
+			text: "abcdefghij",
+			want: []Section{{Text: "abcd"}, {Text: "efgh"}, {Text: "ij"}},
+		},
+		{
+			name: "blank text",
+			size: 10,
+			text: " \n\n\u200b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTextSplitter(util.Config{ChunkSize: tt.size, ChunkOverlap: tt.overlap})
+			got := s.Split(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTextSplitterDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      util.Config
+		wantSize    int
+		wantOverlap int
+	}{
+		{"chars", util.Config{}, defaultChunkChars, 0},
+		{"tokens", util.Config{ChunkUnit: ChunkUnitTokens}, defaultChunkTokens, 0},
+		{"negative overlap", util.Config{ChunkSize: 100, ChunkOverlap: -5}, 100, 0},
+		{"overlap as large as the chunk", util.Config{ChunkSize: 100, ChunkOverlap: 100}, 100, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTextSplitter(tt.config)
+			if s.size != tt.wantSize || s.overlap != tt.wantOverlap {
+				t.Errorf("size %d, overlap %d, want %d and %d", s.size, s.overlap, tt.wantSize, tt.wantOverlap)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+const (
+	// maxToolRounds is how often one answer may go back to the tools; the
+	// round after that has to be answered in text.
+	maxToolRounds      = 3
+	maxToolResultChars = 4000
+	maxToolLogChars    = 500
+
+	defaultPreviousChats = 5
+	maxPreviousChats     = 20
+)
+
+// toolEnv is what a tool may act on: the chat being answered and the user
+// who opened it. It comes from the server, never from the model's
+// arguments, so nothing the user writes can point a tool at another account.
+// chat is as loaded before the answer started; department is where the
+// answer routed it, which may have been set since.
+type toolEnv struct {
+	chat       db.Chat
+	department string
+	botID      uuid.UUID
+	lang       string
+}
+
+// toolResult is what the model is told. handedOff ends the answer: the chat
+// now waits for a person and the handoff notice is the reply.
+type toolResult struct {
+	content   string
+	handedOff bool
+}
+
+type tool struct {
+	spec ToolSpec
+	run  func(b *Bot, ctx context.Context, env toolEnv, args json.RawMessage) (toolResult, error)
+}
+
+// toolRegistry holds every tool the bot can be given. BOT_TOOLS picks which
+// ones it gets; none of them reads or changes anything outside toolEnv.
+var toolRegistry = []tool{
+	{
+		spec: ToolSpec{
+			Name:        "list_previous_chats",
+			Description: "List the user's earlier support chats, newest first, with their label, status and department.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{"limit":{"type":"integer","minimum":1,"maximum":20,"description":"How many chats to list, 5 if omitted."}}}`),
+		},
+		run: (*Bot).listPreviousChats,
+	},
+	{
+		spec: ToolSpec{
+			Name:        "get_account_status",
+			Description: "Get the status of the user's account (for example pending, verified or suspended), their role and when they signed up.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{}}`),
+		},
+		run: (*Bot).getAccountStatus,
+	},
+	{
+		spec: ToolSpec{
+			Name:        "close_chat",
+			Description: "Close this chat. Only use it when the user says their issue is solved or they are done.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{"reason":{"type":"string","description":"Why the chat can be closed."}}}`),
+		},
+		run: (*Bot).closeChat,
+	},
+	{
+		spec: ToolSpec{
+			Name:        "escalate_to_human",
+			Description: "Hand this chat to a human support agent when you cannot help or the user needs a person. Ends your answer.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{"reason":{"type":"string","description":"What the agent needs to know."}},"required":["reason"]}`),
+		},
+		run: (*Bot).escalateToHuman,
+	},
+	{
+		spec: ToolSpec{
+			Name:        "open_follow_up_ticket",
+			Description: "Open a follow-up ticket so the support team gets back to the user later. A chat has at most one open ticket.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{"subject":{"type":"string","description":"One line summary."},"details":{"type":"string","description":"What needs to be done."}},"required":["subject"]}`),
+		},
+		run: (*Bot).openFollowUpTicket,
+	},
+}
+
+// newToolset returns the registry's tools named in names, in that order.
+func newToolset(names []string) ([]tool, error) {
+	var out []tool
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		t, ok := findTool(toolRegistry, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown bot tool %q", name)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func findTool(tools []tool, name string) (tool, bool) {
+	for _, t := range tools {
+		if t.spec.Name == name {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+func (b *Bot) toolSpecs() []ToolSpec {
+	var specs []ToolSpec
+	for _, t := range b.tools {
+		specs = append(specs, t.spec)
+	}
+	return specs
+}
+
+// complete streams the answer to messages, running the tools the model asks
+// for in between. Text from every round reaches the client, so the returned
+// Content is all of it, not just the last round's. It reports true when a
+// tool handed the chat to a person, in which case there is no answer to
+// store.
+func (b *Bot) complete(ctx context.Context, env toolEnv, p persona, messages []ChatMessage, onDelta func(delta string) error) (CompletionResponse, bool, error) {
+	var streamed strings.Builder
+	for round := 0; ; round++ {
+		req := CompletionRequest{
+			Messages:    messages,
+			Temperature: p.temperature,
+			MaxTokens:   p.model.contexts.AnswerTokens(),
+		}
+		if round < maxToolRounds {
+			req.Tools = b.toolSpecs()
+		}
+		resp, err := p.model.completer.Stream(ctx, req, onDelta)
+		if err != nil {
+			return resp, false, err
+		}
+		streamed.WriteString(resp.Content)
+		if len(resp.ToolCalls) == 0 {
+			resp.Content = streamed.String()
+			return resp, false, nil
+		}
+		// Keep what was said before the tool call apart from what follows.
+		if strings.TrimSpace(resp.Content) != "" {
+			if err := onDelta("\n\n"); err != nil {
+				return resp, false, err
+			}
+			streamed.WriteString("\n\n")
+		}
+
+		messages = append(messages, ChatMessage{Role: "assistant", Content: resp.Content, ToolCalls: resp.ToolCalls})
+		for _, call := range resp.ToolCalls {
+			result, handedOff := b.runTool(ctx, env, call)
+			if handedOff {
+				return resp, true, nil
+			}
+			messages = append(messages, result)
+		}
+	}
+}
+
+// runTool runs one call and logs it in the chat. A failing tool does not
+// fail the answer; the model is told what went wrong and carries on.
+func (b *Bot) runTool(ctx context.Context, env toolEnv, call ToolCall) (ChatMessage, bool) {
+	var result toolResult
+	t, ok := findTool(b.tools, call.Name)
+	err := fmt.Errorf("unknown tool %q", call.Name)
+	if ok {
+		result, err = t.run(b, ctx, env, call.Arguments)
+	}
+	content := result.content
+	if err != nil {
+		content = "error: " + err.Error()
+	}
+	content = truncateRunes(content, maxToolResultChars)
+	b.logToolCall(ctx, env, call, content, err != nil)
+
+	return ChatMessage{Role: "tool", Content: content, ToolCallID: call.ID, ToolName: call.Name}, result.handedOff
+}
+
+// logToolCall stores the call as a system message. It is not broadcast:
+// the log is for admins reviewing the chat, not for the user.
+func (b *Bot) logToolCall(ctx context.Context, env toolEnv, call ToolCall, result string, failed bool) {
+	args := call.Arguments
+	if !json.Valid(args) {
+		args, _ = json.Marshal(string(call.Arguments))
+	}
+	_, err := b.store.LogToolCallTx(ctx, db.LogToolCallTxParams{
+		CreateMessageParams: db.CreateMessageParams{
+			ChatExternalID:   env.chat.ChatExternalID,
+			SenderExternalID: env.botID,
+			Content:          truncateRunes(fmt.Sprintf("Tool %s(%s): %s", call.Name, call.Arguments, result), maxToolLogChars),
+			IsSystemMessage:  true,
+			IsAdminMessage:   false,
+		},
+		CallID:    call.ID,
+		ToolName:  call.Name,
+		Arguments: args,
+		Result:    result,
+		IsError:   failed,
+	})
+	if err != nil {
+		fmt.Printf("Cannot log tool call %s in %s: %v\n", call.Name, env.chat.ChatExternalID, err)
+	}
+}
+
+func decodeToolArgs(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func toolJSON(v interface{}) (toolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return toolResult{}, err
+	}
+	return toolResult{content: string(data)}, nil
+}
+
+func (b *Bot) listPreviousChats(ctx context.Context, env toolEnv, raw json.RawMessage) (toolResult, error) {
+	var args struct {
+		Limit int `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return toolResult{}, err
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultPreviousChats
+	}
+	if args.Limit > maxPreviousChats {
+		args.Limit = maxPreviousChats
+	}
+
+	chats, err := b.store.Querier.GetChatsByUser(ctx, db.GetChatsByUserParams{
+		UserExternalID: env.chat.UserExternalID,
+		Limit:          int32(args.Limit + 1),
+	})
+	if err != nil {
+		return toolResult{}, err
+	}
+	type previousChat struct {
+		ChatID     string `json:"chat_id"`
+		Label      string `json:"label"`
+		Status     string `json:"status"`
+		Department string `json:"department,omitempty"`
+		StartedAt  string `json:"started_at"`
+	}
+	out := []previousChat{}
+	for _, chat := range chats {
+		if chat.ChatExternalID == env.chat.ChatExternalID || len(out) == args.Limit {
+			continue
+		}
+		out = append(out, previousChat{
+			ChatID:     chat.ChatExternalID.String(),
+			Label:      chat.Label,
+			Status:     chat.Status,
+			Department: chat.Department.String,
+			StartedAt:  chat.CreatedAt.Time.Format(time.DateOnly),
+		})
+	}
+	return toolJSON(out)
+}
+
+func (b *Bot) getAccountStatus(ctx context.Context, env toolEnv, _ json.RawMessage) (toolResult, error) {
+	user, err := b.store.Querier.GetUserByExternalID(ctx, env.chat.UserExternalID)
+	if err != nil {
+		return toolResult{}, err
+	}
+	return toolJSON(map[string]string{
+		"status":       string(user.Status),
+		"role":         user.Role,
+		"member_since": user.CreatedAt.Time.Format(time.DateOnly),
+	})
+}
+
+func (b *Bot) closeChat(ctx context.Context, env toolEnv, _ json.RawMessage) (toolResult, error) {
+	chat, err := b.store.Querier.UpdateChatStatus(ctx, db.UpdateChatStatusParams{
+		ChatExternalID: env.chat.ChatExternalID,
+		Column2:        string(db.ChatStatusTypeClosed),
+	})
+	if err != nil {
+		return toolResult{}, err
+	}
+	b.hub.NotifyAdmins(ws.NewAdminChatItem(chat))
+	return toolJSON(map[string]string{"status": chat.Status})
+}
+
+func (b *Bot) escalateToHuman(ctx context.Context, env toolEnv, raw json.RawMessage) (toolResult, error) {
+	var args struct {
+		Reason string `json:"reason"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return toolResult{}, err
+	}
+	h := handoff{reason: HandoffReasonBotEscalation, detail: strings.TrimSpace(args.Reason)}
+	if err := b.handOff(ctx, env.chat, env.botID, env.lang, h); err != nil {
+		return toolResult{}, err
+	}
+	return toolResult{content: `{"status":"waiting"}`, handedOff: true}, nil
+}
+
+func (b *Bot) openFollowUpTicket(ctx context.Context, env toolEnv, raw json.RawMessage) (toolResult, error) {
+	var args struct {
+		Subject string `json:"subject"`
+		Details string `json:"details"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return toolResult{}, err
+	}
+	args.Subject = strings.TrimSpace(args.Subject)
+	if args.Subject == "" {
+		return toolResult{}, errors.New("subject is required")
+	}
+	details := strings.TrimSpace(args.Details)
+
+	ticket, err := b.store.Querier.CreateFollowUpTicket(ctx, db.CreateFollowUpTicketParams{
+		ChatExternalID: env.chat.ChatExternalID,
+		UserExternalID: env.chat.UserExternalID,
+		Department:     pgtype.Text{String: env.department, Valid: env.department != ""},
+		Subject:        truncateRunes(args.Subject, 200),
+		Details:        pgtype.Text{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		return toolResult{}, err
+	}
+	return toolJSON(map[string]string{
+		"ticket_id": ticket.TicketExternalID.String(),
+		"subject":   ticket.Subject,
+		"status":    ticket.Status,
+	})
+}
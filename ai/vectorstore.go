@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	VectorStorePgvector = "pgvector"
+	VectorStoreMemory   = "memory"
+
+	defaultEmbeddingDimensions = 1536
+)
+
+type ScoredChunk struct {
+	Chunk
+	Score float64
+}
+
+// SearchFilter narrows a search to chunks with matching metadata. Empty
+// fields match everything. Chunks without a department are general knowledge
+// and match any department; chunks whose language could not be detected
+// match any language.
+//
+// Departments and Sources are a persona's knowledge scope: a chunk is in
+// scope when its department or its source is listed. General knowledge is
+// in scope only when Departments is set.
+type SearchFilter struct {
+	Department  string
+	Language    string
+	Departments []string
+	Sources     []uuid.UUID
+}
+
+func (f SearchFilter) matches(c Chunk) bool {
+	if f.Department != "" && c.Department != "" && f.Department != c.Department {
+		return false
+	}
+	if f.Language != "" && c.Language != "" && f.Language != c.Language {
+		return false
+	}
+	return f.inScope(c)
+}
+
+func (f SearchFilter) inScope(c Chunk) bool {
+	if len(f.Departments) == 0 && len(f.Sources) == 0 {
+		return true
+	}
+	if len(f.Departments) > 0 && (c.Department == "" || slices.Contains(f.Departments, c.Department)) {
+		return true
+	}
+	return c.SourceID != uuid.Nil && slices.Contains(f.Sources, c.SourceID)
+}
+
+// scopeArgs returns the knowledge scope as text arrays for the SQL filter.
+// Neither is nil, since pgx sends a nil slice as NULL.
+func (f SearchFilter) scopeArgs() ([]string, []string) {
+	departments := append([]string{}, f.Departments...)
+	sources := make([]string, 0, len(f.Sources))
+	for _, id := range f.Sources {
+		sources = append(sources, id.String())
+	}
+	return departments, sources
+}
+
+// VectorStore keeps chunk embeddings and answers nearest-neighbour queries
+// by cosine similarity. Add keeps a chunk's ID when it has one and fills in
+// the ID of the others. Search returns at most topK ready chunks, best first.
+type VectorStore interface {
+	Add(ctx context.Context, chunks []Chunk) error
+	Search(ctx context.Context, embedding []float64, topK int, filter SearchFilter) ([]ScoredChunk, error)
+	SearchText(ctx context.Context, query string, topK int, filter SearchFilter) ([]ScoredChunk, error)
+	SourceChunks(ctx context.Context, source SourceRef) ([]ChunkRef, error)
+	SetStatus(ctx context.Context, ids []uuid.UUID, status string) error
+}
+
+func NewVectorStore(ctx context.Context, config util.Config, pool *pgxpool.Pool) (VectorStore, error) {
+	switch strings.ToLower(strings.TrimSpace(config.VectorStore)) {
+	case "", VectorStorePgvector:
+		store := NewPgVectorStore(pool, embeddingDimensions(config))
+		if err := store.EnsureIndex(ctx); err != nil {
+			return nil, err
+		}
+		return store, nil
+	case VectorStoreMemory:
+		return NewMemoryVectorStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown vector store %q", config.VectorStore)
+	}
+}
+
+func embeddingDimensions(config util.Config) int {
+	if config.AIEmbeddingDims > 0 {
+		return config.AIEmbeddingDims
+	}
+	return defaultEmbeddingDimensions
+}
+
+// queryTerms splits a free-text query into lower-cased words made of letters
+// and digits, dropping duplicates. The output is safe to join into a tsquery.
+func queryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[f] {
+			seen[f] = true
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// vectorLiteral formats an embedding the way pgvector parses it: [1,2,3].
+func vectorLiteral(v []float64) string {
+	var b strings.Builder
+	b.Grow(len(v) * 10)
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(f, 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -5,10 +5,12 @@ import (
 )
 
 type CreateChatRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	Label     string `json:"label"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Email      string `json:"email"`
+	Label      string `json:"label"`
+	Department string `json:"department"`
+	EntryPoint string `json:"entry_point"`
 }
 
 type UpdateChatRequest struct {
@@ -17,12 +19,14 @@ type UpdateChatRequest struct {
 }
 
 type CreateChatResponse struct {
-	ChatID         string    `json:"chat_id"`
-	UserExternalID string    `json:"user_external_id"`
-	Label          string    `json:"label"`
-	Status         string    `json:"status"`
-	AccessToken    string    `json:"access_token,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
+	ChatID         string          `json:"chat_id"`
+	UserExternalID string          `json:"user_external_id"`
+	Label          string          `json:"label"`
+	Status         string          `json:"status"`
+	Department     string          `json:"department,omitempty"`
+	Persona        *PersonaProfile `json:"persona,omitempty"`
+	AccessToken    string          `json:"access_token,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
 
 type CloseChatRequest struct {
@@ -30,19 +34,23 @@ type CloseChatRequest struct {
 }
 
 type ChatResponse struct {
-	ChatExternalID  string    `json:"chat_external_id"`
-	UserExternalID  string    `json:"user_external_id"`
-	AdminExternalID *string   `json:"admin_external_id,omitempty"`
-	Label           string    `json:"label"`
-	Status          string    `json:"status"`
-	Score           int64     `json:"score"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	UnreadCount     int       `json:"unread_count,omitempty"`
-	LastMessage     string    `json:"last_message,omitempty"`
+	ChatExternalID  string           `json:"chat_external_id"`
+	UserExternalID  string           `json:"user_external_id"`
+	AdminExternalID *string          `json:"admin_external_id,omitempty"`
+	Label           string           `json:"label"`
+	Status          string           `json:"status"`
+	Department      string           `json:"department,omitempty"`
+	EntryPoint      string           `json:"entry_point,omitempty"`
+	Persona         string           `json:"persona,omitempty"`
+	Score           int64            `json:"score"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	UnreadCount     int              `json:"unread_count,omitempty"`
+	LastMessage     string           `json:"last_message,omitempty"`
+	Handoff         *HandoffResponse `json:"handoff,omitempty"`
 }
 
 type GetChatsRequest struct {
 	Limit  int32 `form:"limit"`
 	Offset int32 `form:"offset"`
-}
\ No newline at end of file
+}
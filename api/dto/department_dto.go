@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type UpsertDepartmentRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Keywords []string `json:"keywords"`
+}
+
+type DepartmentResponse struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Keywords  []string  `json:"keywords"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DepartmentQueueRequest struct {
+	Department string `form:"department"`
+	Limit      int32  `form:"limit"`
+	Offset     int32  `form:"offset"`
+}
+
+type SetChatDepartmentRequest struct {
+	Department string `json:"department"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type ListDocumentsRequest struct {
+	Limit int32 `form:"limit"`
+}
+
+type DocumentResponse struct {
+	ID          string     `json:"id"`
+	Filename    string     `json:"filename"`
+	MimeType    string     `json:"mime_type"`
+	Size        int64      `json:"size"`
+	Department  string     `json:"department,omitempty"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	UploadedAt  time.Time  `json:"uploaded_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
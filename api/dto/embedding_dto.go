@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type EmbeddingProcessStats struct {
+	Model         string  `json:"model"`
+	Requested     int64   `json:"requested"`
+	CacheHits     int64   `json:"cache_hits"`
+	Merged        int64   `json:"merged"`
+	Embedded      int64   `json:"embedded"`
+	ProviderCalls int64   `json:"provider_calls"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+type EmbeddingCacheModelStats struct {
+	Model      string     `json:"model"`
+	Entries    int64      `json:"entries"`
+	Hits       int64      `json:"hits"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type EmbeddingStatsResponse struct {
+	// Process covers this server since it started; Cache covers everything
+	// stored, across restarts.
+	Process EmbeddingProcessStats      `json:"process"`
+	Cache   []EmbeddingCacheModelStats `json:"cache"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type ListDownvotedChunksRequest struct {
+	SourceID string `form:"source_id"`
+	Limit    int32  `form:"limit"`
+}
+
+type DownvotedChunkResponse struct {
+	ChunkExternalID  string    `json:"chunk_external_id"`
+	SourceID         string    `json:"source_id,omitempty"`
+	SourceFilename   string    `json:"source_filename"`
+	SourcePage       int32     `json:"source_page,omitempty"`
+	HeadingPath      string    `json:"heading_path,omitempty"`
+	Status           string    `json:"status"`
+	Excerpt          string    `json:"excerpt"`
+	FeedbackScore    int64     `json:"feedback_score"`
+	RatedAnswers     int32     `json:"rated_answers"`
+	UpvotedAnswers   int32     `json:"upvoted_answers"`
+	DownvotedAnswers int32     `json:"downvoted_answers"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type ListDownvotedSourcesRequest struct {
+	Limit int32 `form:"limit"`
+}
+
+// DownvotedSourceResponse adds up the downvoted chunks of a document. The
+// answer counts are per chunk, so an answer citing two of its chunks counts
+// twice.
+type DownvotedSourceResponse struct {
+	SourceID         string `json:"source_id,omitempty"`
+	SourceFilename   string `json:"source_filename"`
+	DownvotedChunks  int64  `json:"downvoted_chunks"`
+	FeedbackScore    int64  `json:"feedback_score"`
+	RatedAnswers     int64  `json:"rated_answers"`
+	DownvotedAnswers int64  `json:"downvoted_answers"`
+}
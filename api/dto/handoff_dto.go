@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type HandoffResponse struct {
+	HandoffExternalID string    `json:"handoff_external_id"`
+	MessageExternalID string    `json:"message_external_id,omitempty"`
+	Reason            string    `json:"reason"`
+	Detail            string    `json:"detail,omitempty"`
+	Similarity        *float64  `json:"similarity,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// PromoteKnowledgeRequest approves an admin reply as an answer the bot may
+// reuse. The question defaults to the last user message before the answer;
+// Question and Answer replace the stored wording when set.
+type PromoteKnowledgeRequest struct {
+	AnswerMessageID   string `json:"answer_message_id" binding:"required"`
+	QuestionMessageID string `json:"question_message_id"`
+	Question          string `json:"question"`
+	Answer            string `json:"answer"`
+}
+
+type ListKnowledgeRequest struct {
+	ChatID string `form:"chat_id"`
+	Limit  int32  `form:"limit"`
+}
+
+type KnowledgeResponse struct {
+	KnowledgeExternalID string    `json:"knowledge_external_id"`
+	ChatExternalID      string    `json:"chat_external_id,omitempty"`
+	QuestionMessageID   string    `json:"question_message_id,omitempty"`
+	AnswerMessageID     string    `json:"answer_message_id,omitempty"`
+	ChunkExternalID     string    `json:"chunk_external_id,omitempty"`
+	Question            string    `json:"question"`
+	Answer              string    `json:"answer"`
+	Department          string    `json:"department,omitempty"`
+	Language            string    `json:"language,omitempty"`
+	CreatedBy           string    `json:"created_by,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
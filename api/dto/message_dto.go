@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type SendMessageRequest struct {
 	ChatExternalID string   `json:"chat_external_id"`
@@ -24,8 +27,34 @@ type MessageResponse struct {
 	UpdatedAt         time.Time    `json:"updated_at"`
 	Attachments       []Attachment `json:"attachments"`
 	Reactions         []Reaction   `json:"reactions"`
+	Citations         []Citation   `json:"citations"`
+	ToolCalls         []ToolCall   `json:"tool_calls,omitempty"`
 }
 
+type Citation struct {
+	ChunkExternalID string `json:"chunk_external_id,omitempty"`
+	SourceID        string `json:"source_id,omitempty"`
+	SourceFilename  string `json:"source_filename"`
+	SourcePage      int32  `json:"source_page,omitempty"`
+	Position        int32  `json:"position"`
+}
+
+// ToolCall is a tool the bot ran while answering, shown to staff on the
+// system message that logs it.
+type ToolCall struct {
+	ToolCallExternalID string          `json:"tool_call_external_id"`
+	ToolName           string          `json:"tool_name"`
+	Arguments          json.RawMessage `json:"arguments"`
+	Result             string          `json:"result"`
+	IsError            bool            `json:"is_error"`
+	CreatedAt          time.Time       `json:"created_at"`
+}
+
+// ReactionRequest is used as JSON to add a reaction and as the query of
+// the request removing one.
+type ReactionRequest struct {
+	Reaction string `json:"reaction" form:"reaction" binding:"required,oneof=👍 👎 👌 🚩"`
+}
 
 type EditMessageRequest struct {
 	MessageExternalID string `json:"message_external_id"`
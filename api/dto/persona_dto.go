@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// UpsertPersonaRequest describes one bot persona. Empty settings fall back
+// to the ones in app.env: PromptName to the default template, ChatModel to
+// AI_CHAT_MODEL, and so on. Departments and SourceIDs limit what the persona
+// answers from; Departments also routes chats in those departments to it.
+type UpsertPersonaRequest struct {
+	DisplayName          string   `json:"display_name" binding:"required"`
+	AvatarURL            string   `json:"avatar_url"`
+	PromptName           string   `json:"prompt_name"`
+	Departments          []string `json:"departments"`
+	SourceIDs            []string `json:"source_ids"`
+	ChatModel            string   `json:"chat_model"`
+	Temperature          *float64 `json:"temperature" binding:"omitempty,min=0,max=2"`
+	HandoffMinSimilarity *float64 `json:"handoff_min_similarity" binding:"omitempty,min=0,max=1"`
+	HandoffPhrases       []string `json:"handoff_phrases"`
+	LabelKeywords        []string `json:"label_keywords"`
+	EntryPoints          []string `json:"entry_points"`
+}
+
+type PersonaResponse struct {
+	Slug                 string    `json:"slug"`
+	DisplayName          string    `json:"display_name"`
+	AvatarURL            string    `json:"avatar_url,omitempty"`
+	PromptName           string    `json:"prompt_name"`
+	Departments          []string  `json:"departments"`
+	SourceIDs            []string  `json:"source_ids"`
+	ChatModel            string    `json:"chat_model,omitempty"`
+	Temperature          *float64  `json:"temperature,omitempty"`
+	HandoffMinSimilarity *float64  `json:"handoff_min_similarity,omitempty"`
+	HandoffPhrases       []string  `json:"handoff_phrases"`
+	LabelKeywords        []string  `json:"label_keywords"`
+	EntryPoints          []string  `json:"entry_points"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// PersonaProfile is what chat clients need to show who is answering.
+type PersonaProfile struct {
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+type SetChatPersonaRequest struct {
+	Persona string `json:"persona"`
+}
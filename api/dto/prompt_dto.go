@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+type CreatePromptTemplateRequest struct {
+	Name          string `json:"name" binding:"required"`
+	SystemPrompt  string `json:"system_prompt" binding:"required"`
+	ContextPrompt string `json:"context_prompt" binding:"required"`
+	Activate      bool   `json:"activate"`
+}
+
+type ListPromptTemplatesRequest struct {
+	Name string `form:"name"`
+}
+
+type PromptTemplateResponse struct {
+	TemplateExternalID string    `json:"template_external_id"`
+	Name               string    `json:"name"`
+	Version            int32     `json:"version"`
+	SystemPrompt       string    `json:"system_prompt"`
+	ContextPrompt      string    `json:"context_prompt"`
+	IsActive           bool      `json:"is_active"`
+	CreatedBy          string    `json:"created_by,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// PreviewPromptRequest renders a stored version when TemplateID is set, the
+// given prompts when they are, and the live default template otherwise.
+type PreviewPromptRequest struct {
+	TemplateID    string `json:"template_id"`
+	SystemPrompt  string `json:"system_prompt"`
+	ContextPrompt string `json:"context_prompt"`
+	Question      string `json:"question" binding:"required"`
+	Language      string `json:"language"`
+	Department    string `json:"department"`
+	Generate      bool   `json:"generate"`
+}
+
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type PreviewPromptResponse struct {
+	Messages     []PromptMessage `json:"messages"`
+	Sources      []Citation      `json:"sources"`
+	Answer       string          `json:"answer,omitempty"`
+	Tokens       int             `json:"tokens"`
+	Budget       int             `json:"budget"`
+	AnswerTokens int             `json:"answer_tokens"`
+	Candidates   int             `json:"candidates"`
+	Duplicates   int             `json:"duplicates"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type ListTicketsRequest struct {
+	Status     string `form:"status" binding:"omitempty,oneof=open resolved"`
+	Department string `form:"department"`
+	Limit      int32  `form:"limit"`
+	Offset     int32  `form:"offset"`
+}
+
+type TicketResponse struct {
+	TicketExternalID string     `json:"ticket_external_id"`
+	ChatExternalID   string     `json:"chat_external_id"`
+	UserExternalID   string     `json:"user_external_id"`
+	Department       string     `json:"department,omitempty"`
+	Subject          string     `json:"subject"`
+	Details          string     `json:"details,omitempty"`
+	Status           string     `json:"status"`
+	ResolvedBy       string     `json:"resolved_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+}
@@ -4,27 +4,33 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
 	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
 	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
 	"github.com/zahra-pzk/Chatbot_Project3/token"
 	"github.com/zahra-pzk/Chatbot_Project3/util"
 )
 
+const defaultChatLabel = "Empty Label"
+
 type ChatHandler struct {
-	store      *db.SQLStore
-	tokenMaker token.Maker
-	config     util.Config
+	store       *db.SQLStore
+	tokenMaker  token.Maker
+	config      util.Config
+	departments *ai.DepartmentClassifier
 }
 
 func NewChatHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *ChatHandler {
 	return &ChatHandler{
-		store:      store,
-		tokenMaker: tokenMaker,
-		config:     config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		config:      config,
+		departments: ai.NewDepartmentClassifier(store),
 	}
 }
 
@@ -34,6 +40,10 @@ func (h *ChatHandler) StartChat(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
 		return
 	}
+	department, ok := lookupDepartment(c, h.store, req.Department)
+	if !ok {
+		return
+	}
 
 	var userExternalID uuid.UUID
 	var accessToken string
@@ -90,8 +100,28 @@ func (h *ChatHandler) StartChat(c *gin.Context) {
 
 	chatLabel := req.Label
 	if chatLabel == "" {
-		chatLabel = "Empty Label"
+		chatLabel = defaultChatLabel
+	}
+	// A descriptive label is often enough to route the chat before the first
+	// message; otherwise the bot classifies it from what the user writes.
+	if !department.Valid && chatLabel != defaultChatLabel {
+		slug, err := h.departments.Classify(c, chatLabel)
+		if err != nil {
+			fmt.Printf("Cannot classify chat label %q: %v\n", chatLabel, err)
+		}
+		department = pgtype.Text{String: slug, Valid: slug != ""}
+	}
+
+	// The persona is settled now when the entry point, label or department
+	// point to one, so the client can show it before the first answer.
+	var persona *db.BotPersona
+	personas, err := h.store.Querier.ListBotPersonas(c)
+	if err != nil {
+		fmt.Printf("Cannot list personas: %v\n", err)
+	} else if p, ok := ai.MatchPersona(personas, req.EntryPoint, chatLabel, department.String); ok {
+		persona = &p
 	}
+	entryPoint := strings.TrimSpace(req.EntryPoint)
 
 	chatArg := db.CreateChatParams{
 		UserExternalID:  userExternalID,
@@ -99,6 +129,11 @@ func (h *ChatHandler) StartChat(c *gin.Context) {
 		Label:           chatLabel,
 		AdminExternalID: pgtype.UUID{Valid: false},
 		Score:           pgtype.Int8{Int64: 0, Valid: true},
+		Department:      department,
+		EntryPoint:      pgtype.Text{String: entryPoint, Valid: entryPoint != ""},
+	}
+	if persona != nil {
+		chatArg.PersonaSlug = pgtype.Text{String: persona.Slug, Valid: true}
 	}
 
 	chat, err := h.store.Querier.CreateChat(c, chatArg)
@@ -114,9 +149,13 @@ func (h *ChatHandler) StartChat(c *gin.Context) {
 		UserExternalID: chat.UserExternalID.String(),
 		Label:          chat.Label,
 		Status:         string(chat.Status),
+		Department:     chat.Department.String,
 		AccessToken:    accessToken,
 		CreatedAt:      chat.CreatedAt.Time,
 	}
+	if persona != nil {
+		rsp.Persona = mapPersonaToProfile(*persona)
+	}
 
 	c.JSON(http.StatusOK, rsp)
 }
@@ -173,3 +212,23 @@ func (h *ChatHandler) DeleteChat(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "chat deleted"})
 }
+
+func mapChatToDTO(chat db.Chat) dto.ChatResponse {
+	rsp := dto.ChatResponse{
+		ChatExternalID: chat.ChatExternalID.String(),
+		UserExternalID: chat.UserExternalID.String(),
+		Label:          chat.Label,
+		Status:         chat.Status,
+		Department:     chat.Department.String,
+		EntryPoint:     chat.EntryPoint.String,
+		Persona:        chat.PersonaSlug.String,
+		Score:          chat.Score.Int64,
+		CreatedAt:      chat.CreatedAt.Time,
+		UpdatedAt:      chat.UpdatedAt.Time,
+	}
+	if chat.AdminExternalID.Valid {
+		adminID := uuid.UUID(chat.AdminExternalID.Bytes).String()
+		rsp.AdminExternalID = &adminID
+	}
+	return rsp
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+var departmentSlug = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type DepartmentHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+}
+
+func NewDepartmentHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *DepartmentHandler {
+	return &DepartmentHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+func (h *DepartmentHandler) ListDepartments(c *gin.Context) {
+	departments, err := h.store.Querier.ListDepartments(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.DepartmentResponse{}
+	for _, d := range departments {
+		rsp = append(rsp, mapDepartmentToDTO(d))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *DepartmentHandler) UpsertDepartment(c *gin.Context) {
+	slug := c.Param("slug")
+	if !departmentSlug.MatchString(slug) {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("department slug must be lowercase letters, digits and dashes")))
+		return
+	}
+	var req dto.UpsertDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	keywords := []string{}
+	for _, kw := range req.Keywords {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			keywords = append(keywords, kw)
+		}
+	}
+
+	department, err := h.store.Querier.UpsertDepartment(c, db.UpsertDepartmentParams{
+		Slug:     slug,
+		Name:     strings.TrimSpace(req.Name),
+		Keywords: keywords,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, mapDepartmentToDTO(department))
+}
+
+// DeleteDepartment removes the department from routing. Chats and documents
+// already tagged with it keep the tag.
+func (h *DepartmentHandler) DeleteDepartment(c *gin.Context) {
+	if err := h.store.Querier.DeleteDepartment(c, c.Param("slug")); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "department deleted"})
+}
+
+// ListQueue returns the chats of one department that no admin has picked up
+// yet, oldest first, with chats the bot handed off on top and the reason
+// attached. Without a department it lists the chats that could not
+// be routed.
+func (h *DepartmentHandler) ListQueue(c *gin.Context) {
+	var req dto.DepartmentQueueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	chats, err := h.store.Querier.ListDepartmentQueue(c, db.ListDepartmentQueueParams{
+		Department: pgtype.Text{String: req.Department, Valid: req.Department != ""},
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.ChatResponse{}
+	for _, chat := range chats {
+		item := mapChatToDTO(chat)
+		if chat.Status == string(db.ChatStatusTypeWaiting) {
+			if handoff, err := h.store.Querier.GetLatestHandoffByChat(c, chat.ChatExternalID); err == nil {
+				handoffDTO := mapHandoffToDTO(handoff)
+				item.Handoff = &handoffDTO
+			}
+		}
+		rsp = append(rsp, item)
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// SetChatDepartment moves a chat to another department's queue, or back to
+// the unrouted queue when the department is empty.
+func (h *DepartmentHandler) SetChatDepartment(c *gin.Context) {
+	chatID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid chat id")))
+		return
+	}
+	var req dto.SetChatDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	department, ok := lookupDepartment(c, h.store, req.Department)
+	if !ok {
+		return
+	}
+
+	chat, err := h.store.Querier.SetChatDepartment(c, db.SetChatDepartmentParams{
+		ChatExternalID: chatID,
+		Department:     department,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("chat not found")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, mapChatToDTO(chat))
+}
+
+// lookupDepartment checks that slug names a configured department. An empty
+// slug is allowed and means "no department".
+func lookupDepartment(c *gin.Context, store *db.SQLStore, slug string) (pgtype.Text, bool) {
+	if slug == "" {
+		return pgtype.Text{}, true
+	}
+	department, err := store.Querier.GetDepartment(c, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("unknown department")))
+			return pgtype.Text{}, false
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return pgtype.Text{}, false
+	}
+	return pgtype.Text{String: department.Slug, Valid: true}, true
+}
+
+func mapDepartmentToDTO(d db.Department) dto.DepartmentResponse {
+	keywords := d.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+	return dto.DepartmentResponse{
+		Slug:      d.Slug,
+		Name:      d.Name,
+		Keywords:  keywords,
+		UpdatedAt: d.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/storage"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const maxDocumentSize = 20 << 20
+
+type DocumentHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+	files      storage.Storage
+	documents  *ai.DocumentProcessor
+}
+
+func NewDocumentHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config, files storage.Storage, documents *ai.DocumentProcessor) *DocumentHandler {
+	return &DocumentHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+		files:      files,
+		documents:  documents,
+	}
+}
+
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	if file.Size > maxDocumentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, util.ErrorResponse(fmt.Errorf("file is larger than %d MB", maxDocumentSize>>20)))
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	defer src.Close()
+
+	// Pick the loader from the content first, so a missing or wrong
+	// extension does not decide how the file is read.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	mimeType := ai.DetectMime(file.Filename, file.Header.Get("Content-Type"), head[:n])
+	if mimeType == "" {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("unsupported file format")))
+		return
+	}
+
+	department, ok := lookupDepartment(c, h.store, c.PostForm("department"))
+	if !ok {
+		return
+	}
+
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	extension := strings.ToLower(filepath.Ext(file.Filename))
+	key := fmt.Sprintf("documents/%s%s", uuid.New(), extension)
+
+	size, err := h.files.Save(c, key, src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	source, err := h.store.Querier.CreateSourceFile(c, db.CreateSourceFileParams{
+		StorageKey: key,
+		Filename:   pgtype.Text{String: filepath.Base(file.Filename), Valid: true},
+		MimeType:   pgtype.Text{String: mimeType, Valid: true},
+		SizeBytes:  pgtype.Int8{Int64: size, Valid: true},
+		UploadedBy: pgtype.UUID{Bytes: payload.UserExternalID, Valid: true},
+		Department: department,
+	})
+	if err != nil {
+		h.files.Delete(c, key)
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	if err := h.documents.Enqueue(c, source.SourceExternalID); err != nil {
+		// Nothing will ever process it, so do not leave it stuck as pending.
+		h.store.Querier.DeleteSourceFile(c, source.SourceExternalID)
+		h.files.Delete(c, key)
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusAccepted, mapSourceFileToDTO(source))
+}
+
+func (h *DocumentHandler) ListDocuments(c *gin.Context) {
+	var req dto.ListDocumentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	sources, err := h.store.Querier.ListUploadedSources(c, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.DocumentResponse{}
+	for _, s := range sources {
+		rsp = append(rsp, mapSourceFileToDTO(s))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *DocumentHandler) ReprocessDocument(c *gin.Context) {
+	source, ok := h.getIdleSource(c)
+	if !ok {
+		return
+	}
+
+	source, err := h.store.Querier.ResetSourceStatus(c, source.SourceExternalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	if err := h.documents.Enqueue(c, source.SourceExternalID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusAccepted, mapSourceFileToDTO(source))
+}
+
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	source, ok := h.getIdleSource(c)
+	if !ok {
+		return
+	}
+
+	sourceID := pgtype.UUID{Bytes: source.SourceExternalID, Valid: true}
+	if err := h.store.Querier.DeleteChunksBySource(c, sourceID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	if err := h.files.Delete(c, source.StorageKey); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	if err := h.store.Querier.DeleteSourceFile(c, source.SourceExternalID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "document deleted"})
+}
+
+// getIdleSource loads the document named in the URL and refuses to touch it
+// while the worker is in the middle of ingesting it.
+func (h *DocumentHandler) getIdleSource(c *gin.Context) (db.SourceFile, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid document id")))
+		return db.SourceFile{}, false
+	}
+
+	source, err := h.store.Querier.GetSourceByExternalID(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("document not found")))
+			return db.SourceFile{}, false
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return db.SourceFile{}, false
+	}
+	if source.Status.String == ai.SourceStatusProcessing {
+		c.JSON(http.StatusConflict, util.ErrorResponse(errors.New("document is being processed")))
+		return db.SourceFile{}, false
+	}
+	return source, true
+}
+
+func mapSourceFileToDTO(s db.SourceFile) dto.DocumentResponse {
+	rsp := dto.DocumentResponse{
+		ID:         s.SourceExternalID.String(),
+		Filename:   s.Filename.String,
+		MimeType:   s.MimeType.String,
+		Size:       s.SizeBytes.Int64,
+		Department: s.Department.String,
+		Status:     s.Status.String,
+		Error:      s.ErrorMessage.String,
+		UploadedAt: s.UploadedAt.Time,
+	}
+	if s.ProcessedAt.Valid {
+		rsp.ProcessedAt = &s.ProcessedAt.Time
+	}
+	return rsp
+}
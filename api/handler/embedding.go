@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+type EmbeddingHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+	embeddings *ai.EmbeddingService
+}
+
+func NewEmbeddingHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config, embeddings *ai.EmbeddingService) *EmbeddingHandler {
+	return &EmbeddingHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+		embeddings: embeddings,
+	}
+}
+
+// GetEmbeddingStats reports how often embeddings came from the cache.
+func (h *EmbeddingHandler) GetEmbeddingStats(c *gin.Context) {
+	models, err := h.store.Querier.GetEmbeddingCacheStats(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	stats := h.embeddings.Stats()
+	rsp := dto.EmbeddingStatsResponse{
+		Process: dto.EmbeddingProcessStats{
+			Model:         stats.Model,
+			Requested:     stats.Requested,
+			CacheHits:     stats.CacheHits,
+			Merged:        stats.Merged,
+			Embedded:      stats.Embedded,
+			ProviderCalls: stats.ProviderCalls,
+			HitRate:       stats.HitRate,
+		},
+		Cache: []dto.EmbeddingCacheModelStats{},
+	}
+	for _, m := range models {
+		item := dto.EmbeddingCacheModelStats{
+			Model:   m.Model,
+			Entries: m.Entries,
+			Hits:    m.Hits,
+		}
+		if m.LastUsedAt.Valid {
+			item.LastUsedAt = &m.LastUsedAt.Time
+		}
+		rsp.Cache = append(rsp.Cache, item)
+	}
+	c.JSON(http.StatusOK, rsp)
+}
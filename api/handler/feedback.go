@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+// FeedbackHandler reports which chunks and documents sit behind the answers
+// users downvote, so admins know what to fix first.
+type FeedbackHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+}
+
+func NewFeedbackHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *FeedbackHandler {
+	return &FeedbackHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+func (h *FeedbackHandler) ListDownvotedChunks(c *gin.Context) {
+	var req dto.ListDownvotedChunksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	arg := db.ListDownvotedChunksParams{RowLimit: req.Limit}
+	if req.SourceID != "" {
+		sourceID, err := uuid.Parse(req.SourceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid source id")))
+			return
+		}
+		arg.SourceID = pgtype.UUID{Bytes: sourceID, Valid: true}
+	}
+
+	chunks, err := h.store.Querier.ListDownvotedChunks(c, arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := make([]dto.DownvotedChunkResponse, 0, len(chunks))
+	for _, ch := range chunks {
+		item := dto.DownvotedChunkResponse{
+			ChunkExternalID:  ch.ChunkExternalID.String(),
+			SourceFilename:   ch.SourceFilename.String,
+			SourcePage:       ch.SourcePage.Int32,
+			HeadingPath:      ch.HeadingPath.String,
+			Status:           ch.Status.String,
+			Excerpt:          ch.Excerpt,
+			FeedbackScore:    ch.FeedbackScore,
+			RatedAnswers:     ch.RatedAnswers,
+			UpvotedAnswers:   ch.UpvotedAnswers,
+			DownvotedAnswers: ch.DownvotedAnswers,
+			UpdatedAt:        ch.UpdatedAt.Time,
+		}
+		if ch.SourceID.Valid {
+			item.SourceID = uuid.UUID(ch.SourceID.Bytes).String()
+		}
+		rsp = append(rsp, item)
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *FeedbackHandler) ListDownvotedSources(c *gin.Context) {
+	var req dto.ListDownvotedSourcesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	sources, err := h.store.Querier.ListDownvotedSources(c, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := make([]dto.DownvotedSourceResponse, 0, len(sources))
+	for _, s := range sources {
+		item := dto.DownvotedSourceResponse{
+			SourceFilename:   s.SourceFilename,
+			DownvotedChunks:  s.DownvotedChunks,
+			FeedbackScore:    s.FeedbackScore,
+			RatedAnswers:     s.RatedAnswers,
+			DownvotedAnswers: s.DownvotedAnswers,
+		}
+		if s.SourceID.Valid {
+			item.SourceID = uuid.UUID(s.SourceID.Bytes).String()
+		}
+		rsp = append(rsp, item)
+	}
+	c.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+type HandoffHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+}
+
+func NewHandoffHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *HandoffHandler {
+	return &HandoffHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+// ListHandoffs returns every time the bot gave the chat up to a person,
+// newest first, with the reason it did so.
+func (h *HandoffHandler) ListHandoffs(c *gin.Context) {
+	chatID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid chat id")))
+		return
+	}
+
+	handoffs, err := h.store.Querier.ListHandoffsByChat(c, chatID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.HandoffResponse{}
+	for _, handoff := range handoffs {
+		rsp = append(rsp, mapHandoffToDTO(handoff))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func mapHandoffToDTO(h db.ChatHandoff) dto.HandoffResponse {
+	rsp := dto.HandoffResponse{
+		HandoffExternalID: h.HandoffExternalID.String(),
+		Reason:            h.Reason,
+		Detail:            h.Detail.String,
+		CreatedAt:         h.CreatedAt.Time,
+	}
+	if h.MessageExternalID.Valid {
+		rsp.MessageExternalID = uuid.UUID(h.MessageExternalID.Bytes).String()
+	}
+	if h.Similarity.Valid {
+		similarity := h.Similarity.Float64
+		rsp.Similarity = &similarity
+	}
+	return rsp
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/jobs"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+type JobHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+}
+
+func NewJobHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *JobHandler {
+	return &JobHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	var req dto.ListJobsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	switch req.Status {
+	case "", jobs.StatusQueued, jobs.StatusRunning, jobs.StatusSucceeded, jobs.StatusDead:
+	default:
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid job status")))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	counts, err := h.store.Querier.CountJobsByStatus(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	list, err := h.store.Querier.ListJobs(c, db.ListJobsParams{
+		Status:   req.Status,
+		RowLimit: req.Limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := dto.JobQueueResponse{
+		Counts: map[string]int64{},
+		Jobs:   []dto.JobResponse{},
+	}
+	for _, row := range counts {
+		rsp.Counts[row.Status] = row.Total
+	}
+	for _, j := range list {
+		rsp.Jobs = append(rsp.Jobs, mapJobToDTO(j))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid job id")))
+		return
+	}
+
+	job, err := h.store.Querier.RetryDeadJob(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("no dead job with this id")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, mapJobToDTO(job))
+}
+
+func mapJobToDTO(j db.Job) dto.JobResponse {
+	rsp := dto.JobResponse{
+		ID:          j.JobExternalID.String(),
+		Kind:        j.Kind,
+		Payload:     j.Payload,
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt.Time,
+		LockedBy:    j.LockedBy.String,
+		LastError:   j.LastError.String,
+		CreatedAt:   j.CreatedAt.Time,
+	}
+	if j.FinishedAt.Valid {
+		rsp.FinishedAt = &j.FinishedAt.Time
+	}
+	return rsp
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+type KnowledgeHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+	knowledge  *ai.KnowledgeBase
+}
+
+func NewKnowledgeHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config, knowledge *ai.KnowledgeBase) *KnowledgeHandler {
+	return &KnowledgeHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+		knowledge:  knowledge,
+	}
+}
+
+// PromoteKnowledge turns an admin's reply in a chat, and the question it
+// answered, into knowledge the bot retrieves like a document chunk.
+func (h *KnowledgeHandler) PromoteKnowledge(c *gin.Context) {
+	chatID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid chat id")))
+		return
+	}
+	var req dto.PromoteKnowledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	answerID, err := uuid.Parse(req.AnswerMessageID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid answer message id")))
+		return
+	}
+
+	chat, err := h.store.Querier.GetChat(c, chatID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("chat not found")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	answer, err := h.store.Querier.GetMessage(c, answerID)
+	if err != nil || answer.ChatExternalID != chatID {
+		if err == nil || errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("answer message not found in this chat")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	if !answer.IsAdminMessage || answer.IsSystemMessage {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("only an admin's reply can be promoted")))
+		return
+	}
+	if sender, err := h.store.Querier.GetUser(c, answer.SenderExternalID); err == nil && sender.Role == string(db.RoleTypeSystem) {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("the bot's own answers cannot be promoted")))
+		return
+	}
+
+	question, ok := h.findQuestion(c, chat, answer, req.QuestionMessageID)
+	if !ok {
+		return
+	}
+
+	params := ai.PromoteParams{
+		Chat:              chat,
+		QuestionMessageID: question.MessageExternalID,
+		AnswerMessageID:   answer.MessageExternalID,
+		Question:          question.Content,
+		Answer:            answer.Content,
+		CreatedBy:         c.MustGet(authorizationPayloadKey).(*token.Payload).UserExternalID,
+	}
+	if strings.TrimSpace(req.Question) != "" {
+		params.Question = req.Question
+	}
+	if strings.TrimSpace(req.Answer) != "" {
+		params.Answer = req.Answer
+	}
+	if strings.TrimSpace(params.Question) == "" || strings.TrimSpace(params.Answer) == "" {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("question and answer must not be empty")))
+		return
+	}
+
+	knowledge, err := h.knowledge.Promote(c, params)
+	if err != nil {
+		if errors.Is(err, ai.ErrKnowledgeExists) {
+			c.JSON(http.StatusConflict, util.ErrorResponse(err))
+			return
+		}
+		if errors.Is(err, ai.ErrCircuitOpen) {
+			c.JSON(http.StatusServiceUnavailable, util.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, mapKnowledgeToDTO(knowledge))
+}
+
+// findQuestion loads the user message the answer replies to, either the one
+// named in the request or the last one before the answer. It writes the
+// error response itself.
+func (h *KnowledgeHandler) findQuestion(c *gin.Context, chat db.Chat, answer db.GetMessageRow, requested string) (db.GetMessageRow, bool) {
+	chatID := chat.ChatExternalID
+	var (
+		question db.GetMessageRow
+		err      error
+	)
+	if requested != "" {
+		questionID, parseErr := uuid.Parse(requested)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid question message id")))
+			return question, false
+		}
+		question, err = h.store.Querier.GetMessage(c, questionID)
+		if err == nil && question.ChatExternalID != chatID {
+			err = pgx.ErrNoRows
+		}
+	} else {
+		var row db.GetQuestionBeforeMessageRow
+		row, err = h.store.Querier.GetQuestionBeforeMessage(c, db.GetQuestionBeforeMessageParams{
+			ChatExternalID: chatID,
+			CreatedAt:      answer.CreatedAt,
+		})
+		question = db.GetMessageRow(row)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("question message not found in this chat")))
+			return question, false
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return question, false
+	}
+	if question.SenderExternalID != chat.UserExternalID || question.IsSystemMessage {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("the question must be a user's message")))
+		return question, false
+	}
+	return question, true
+}
+
+func (h *KnowledgeHandler) ListKnowledge(c *gin.Context) {
+	var req dto.ListKnowledgeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	var chatID pgtype.UUID
+	if req.ChatID != "" {
+		id, err := uuid.Parse(req.ChatID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid chat id")))
+			return
+		}
+		chatID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	list, err := h.store.Querier.ListKnowledge(c, db.ListKnowledgeParams{
+		ChatExternalID: chatID,
+		RowLimit:       req.Limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	rsp := []dto.KnowledgeResponse{}
+	for _, k := range list {
+		rsp = append(rsp, mapKnowledgeToDTO(k))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+// DeleteKnowledge withdraws an entry; the bot stops retrieving it at once.
+func (h *KnowledgeHandler) DeleteKnowledge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid knowledge id")))
+		return
+	}
+	knowledge, err := h.store.Querier.GetKnowledgeByID(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("knowledge not found")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	if err := h.knowledge.Remove(c, knowledge); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "knowledge deleted"})
+}
+
+func mapKnowledgeToDTO(k db.AiKnowledge) dto.KnowledgeResponse {
+	var meta ai.KnowledgeMeta
+	if err := json.Unmarshal(k.SourceMeta, &meta); err != nil || meta.Answer == "" {
+		// Entries written before promotion existed only have the text.
+		meta.Answer = k.SourceText
+	}
+	rsp := dto.KnowledgeResponse{
+		KnowledgeExternalID: k.KnowledgeExternalID.String(),
+		Question:            meta.Question,
+		Answer:              meta.Answer,
+		Department:          meta.Department,
+		Language:            meta.Language,
+		CreatedAt:           k.CreatedAt,
+	}
+	if k.ChatExternalID.Valid {
+		rsp.ChatExternalID = uuid.UUID(k.ChatExternalID.Bytes).String()
+	}
+	if k.QuestionMessageID.Valid {
+		rsp.QuestionMessageID = uuid.UUID(k.QuestionMessageID.Bytes).String()
+	}
+	if k.AnswerMessageID.Valid {
+		rsp.AnswerMessageID = uuid.UUID(k.AnswerMessageID.Bytes).String()
+	}
+	if k.SourceChunkID.Valid {
+		rsp.ChunkExternalID = uuid.UUID(k.SourceChunkID.Bytes).String()
+	}
+	if k.CreatedBy.Valid {
+		rsp.CreatedBy = uuid.UUID(k.CreatedBy.Bytes).String()
+	}
+	return rsp
+}
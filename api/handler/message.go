@@ -106,6 +106,7 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		UpdatedAt:         msg.UpdatedAt.Time,
 		Attachments:       attachments,
 		Reactions:         []dto.Reaction{},
+		Citations:         []dto.Citation{},
 	}
 
 	c.JSON(http.StatusCreated, rsp)
@@ -141,10 +142,36 @@ func (h *MessageHandler) ListMessages(c *gin.Context) {
 		return
 	}
 
+	// The bot logs its tool calls as system messages. Staff see them with
+	// the call details; users do not see them at all.
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	isStaff := payload.Role == string(db.RoleTypeAdmin) || payload.Role == string(db.RoleTypeSuperadmin)
+	toolCalls, err := h.store.Querier.ListToolCallsByChat(c, chatExternalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	callsByMessage := make(map[uuid.UUID][]dto.ToolCall)
+	for _, tc := range toolCalls {
+		callsByMessage[tc.MessageExternalID] = append(callsByMessage[tc.MessageExternalID], dto.ToolCall{
+			ToolCallExternalID: tc.ToolCallExternalID.String(),
+			ToolName:           tc.ToolName,
+			Arguments:          tc.Arguments,
+			Result:             tc.Result,
+			IsError:            tc.IsError,
+			CreatedAt:          tc.CreatedAt.Time,
+		})
+	}
+
 	var rsp []dto.MessageResponse
 	for _, m := range messages {
+		calls := callsByMessage[m.MessageExternalID]
+		if len(calls) > 0 && !isStaff {
+			continue
+		}
 		attachments, _ := h.store.Querier.ListAllAttachmentsByMessage(c, m.MessageExternalID)
 		reactions, _ := h.store.Querier.ListAllReactionsByMessage(c, m.MessageExternalID)
+		citations, _ := h.store.Querier.ListCitationsByMessage(c, m.MessageExternalID)
 
 		var attachDTOs []dto.Attachment
 		for _, a := range attachments {
@@ -186,6 +213,8 @@ func (h *MessageHandler) ListMessages(c *gin.Context) {
 			UpdatedAt:         m.UpdatedAt.Time,
 			Attachments:       attachDTOs,
 			Reactions:         reactDTOs,
+			Citations:         mapCitationsToDTO(citations),
+			ToolCalls:         calls,
 		})
 	}
 
@@ -299,6 +328,94 @@ func (h *MessageHandler) DeleteMessagesByChat(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "all messages in chat deleted"})
 }
 
+// AddReaction rates a message. Reactions roll up into the chat's score and,
+// on bot answers, into the feedback of the chunks the answer cited.
+func (h *MessageHandler) AddReaction(c *gin.Context) {
+	var req dto.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	msg, ok := h.reactableMessage(c, payload)
+	if !ok {
+		return
+	}
+
+	// Column4 stays NULL so reaction_weight scores the reaction.
+	reaction, err := h.store.Querier.InsertReactionWithWeight(c, db.InsertReactionWithWeightParams{
+		MessageExternalID: msg.MessageExternalID,
+		UserExternalID:    payload.UserExternalID,
+		Reaction:          req.Reaction,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.Reaction{
+		ReactionExternalID: reaction.ReactionExternalID.String(),
+		UserExternalID:     reaction.UserExternalID.String(),
+		Reaction:           reaction.Reaction,
+		Score:              reaction.Score,
+		CreatedAt:          reaction.CreatedAt.Time,
+	})
+}
+
+func (h *MessageHandler) RemoveReaction(c *gin.Context) {
+	var req dto.ReactionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	msg, ok := h.reactableMessage(c, payload)
+	if !ok {
+		return
+	}
+
+	err := h.store.Querier.RemoveReaction(c, db.RemoveReactionParams{
+		MessageExternalID: msg.MessageExternalID,
+		UserExternalID:    payload.UserExternalID,
+		Reaction:          req.Reaction,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "reaction removed"})
+}
+
+// reactableMessage loads the message in the path if the caller may react to
+// it: admins anywhere, users in their own chats.
+func (h *MessageHandler) reactableMessage(c *gin.Context, payload *token.Payload) (db.GetMessageRow, bool) {
+	msgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid message id")))
+		return db.GetMessageRow{}, false
+	}
+	msg, err := h.store.Querier.GetMessage(c, msgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, util.ErrorResponse(err))
+		return db.GetMessageRow{}, false
+	}
+
+	if payload.Role == string(db.RoleTypeAdmin) || payload.Role == string(db.RoleTypeSuperadmin) {
+		return msg, true
+	}
+	chat, err := h.store.Querier.GetChat(c, msg.ChatExternalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return db.GetMessageRow{}, false
+	}
+	if chat.UserExternalID != payload.UserExternalID {
+		c.JSON(http.StatusForbidden, util.ErrorResponse(errors.New("permission denied")))
+		return db.GetMessageRow{}, false
+	}
+	return msg, true
+}
+
 func mapEditMessageToDTO(m db.EditMessageRow) dto.MessageResponse {
 	return dto.MessageResponse{
 		MessageExternalID: m.MessageExternalID.String(),
@@ -311,5 +428,25 @@ func mapEditMessageToDTO(m db.EditMessageRow) dto.MessageResponse {
 		UpdatedAt:         m.UpdatedAt.Time,
 		Attachments:       []dto.Attachment{},
 		Reactions:         []dto.Reaction{},
+		Citations:         []dto.Citation{},
+	}
+}
+
+func mapCitationsToDTO(citations []db.MessageCitation) []dto.Citation {
+	rsp := []dto.Citation{}
+	for _, c := range citations {
+		citation := dto.Citation{
+			SourceFilename: c.SourceFilename.String,
+			SourcePage:     c.SourcePage.Int32,
+			Position:       c.Position,
+		}
+		if c.ChunkExternalID.Valid {
+			citation.ChunkExternalID = uuid.UUID(c.ChunkExternalID.Bytes).String()
+		}
+		if c.SourceID.Valid {
+			citation.SourceID = uuid.UUID(c.SourceID.Bytes).String()
+		}
+		rsp = append(rsp, citation)
 	}
+	return rsp
 }
\ No newline at end of file
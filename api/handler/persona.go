@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+// PersonaHandler manages the personas the bot answers as, one per brand or
+// department served from this deployment.
+type PersonaHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+}
+
+func NewPersonaHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *PersonaHandler {
+	return &PersonaHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+func (h *PersonaHandler) ListPersonas(c *gin.Context) {
+	personas, err := h.store.Querier.ListBotPersonas(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.PersonaResponse{}
+	for _, p := range personas {
+		rsp = append(rsp, mapPersonaToDTO(p))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *PersonaHandler) UpsertPersona(c *gin.Context) {
+	slug := c.Param("slug")
+	if !departmentSlug.MatchString(slug) {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("persona slug must be lowercase letters, digits and dashes")))
+		return
+	}
+	var req dto.UpsertPersonaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	promptName := strings.TrimSpace(req.PromptName)
+	if promptName == "" {
+		promptName = ai.DefaultPromptName
+	}
+	if !departmentSlug.MatchString(promptName) {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("template name must be lowercase letters, digits and dashes")))
+		return
+	}
+	departments := trimmedList(req.Departments)
+	for _, d := range departments {
+		if !departmentSlug.MatchString(d) {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("department slug must be lowercase letters, digits and dashes")))
+			return
+		}
+	}
+	sources := []uuid.UUID{}
+	for _, s := range trimmedList(req.SourceIDs) {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid source id")))
+			return
+		}
+		sources = append(sources, id)
+	}
+
+	avatarURL := strings.TrimSpace(req.AvatarURL)
+	chatModel := strings.TrimSpace(req.ChatModel)
+	arg := db.UpsertBotPersonaParams{
+		Slug:           slug,
+		DisplayName:    strings.TrimSpace(req.DisplayName),
+		AvatarUrl:      pgtype.Text{String: avatarURL, Valid: avatarURL != ""},
+		PromptName:     promptName,
+		Departments:    departments,
+		SourceIds:      sources,
+		ChatModel:      pgtype.Text{String: chatModel, Valid: chatModel != ""},
+		HandoffPhrases: trimmedList(req.HandoffPhrases),
+		LabelKeywords:  trimmedList(req.LabelKeywords),
+		EntryPoints:    trimmedList(req.EntryPoints),
+	}
+	if req.Temperature != nil {
+		arg.Temperature = pgtype.Float8{Float64: *req.Temperature, Valid: true}
+	}
+	if req.HandoffMinSimilarity != nil {
+		arg.HandoffMinSimilarity = pgtype.Float8{Float64: *req.HandoffMinSimilarity, Valid: true}
+	}
+
+	persona, err := h.store.Querier.UpsertBotPersona(c, arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, mapPersonaToDTO(persona))
+}
+
+// DeletePersona removes the persona. Its chats go back to being matched on
+// their next message.
+func (h *PersonaHandler) DeletePersona(c *gin.Context) {
+	if err := h.store.Querier.DeleteBotPersona(c, c.Param("slug")); err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "persona deleted"})
+}
+
+// SetChatPersona pins a chat to a persona. An empty persona unpins it, and
+// the bot matches one again on the next message.
+func (h *PersonaHandler) SetChatPersona(c *gin.Context) {
+	chatID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid chat id")))
+		return
+	}
+	var req dto.SetChatPersonaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	var slug pgtype.Text
+	if req.Persona != "" {
+		persona, err := h.store.Querier.GetBotPersona(c, req.Persona)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("unknown persona")))
+				return
+			}
+			c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+			return
+		}
+		slug = pgtype.Text{String: persona.Slug, Valid: true}
+	}
+
+	chat, err := h.store.Querier.SetChatPersona(c, db.SetChatPersonaParams{
+		ChatExternalID: chatID,
+		PersonaSlug:    slug,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("chat not found")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, mapChatToDTO(chat))
+}
+
+func trimmedList(items []string) []string {
+	out := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func mapPersonaToDTO(p db.BotPersona) dto.PersonaResponse {
+	rsp := dto.PersonaResponse{
+		Slug:           p.Slug,
+		DisplayName:    p.DisplayName,
+		AvatarURL:      p.AvatarUrl.String,
+		PromptName:     p.PromptName,
+		Departments:    trimmedList(p.Departments),
+		SourceIDs:      []string{},
+		ChatModel:      p.ChatModel.String,
+		HandoffPhrases: trimmedList(p.HandoffPhrases),
+		LabelKeywords:  trimmedList(p.LabelKeywords),
+		EntryPoints:    trimmedList(p.EntryPoints),
+		UpdatedAt:      p.UpdatedAt.Time,
+	}
+	for _, id := range p.SourceIds {
+		rsp.SourceIDs = append(rsp.SourceIDs, id.String())
+	}
+	if p.Temperature.Valid {
+		rsp.Temperature = &p.Temperature.Float64
+	}
+	if p.HandoffMinSimilarity.Valid {
+		rsp.HandoffMinSimilarity = &p.HandoffMinSimilarity.Float64
+	}
+	return rsp
+}
+
+func mapPersonaToProfile(p db.BotPersona) *dto.PersonaProfile {
+	return &dto.PersonaProfile{
+		Slug:        p.Slug,
+		DisplayName: p.DisplayName,
+		AvatarURL:   p.AvatarUrl.String,
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+type PromptHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+	previewer  *ai.PromptPreviewer
+}
+
+func NewPromptHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config, previewer *ai.PromptPreviewer) *PromptHandler {
+	return &PromptHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+		previewer:  previewer,
+	}
+}
+
+func (h *PromptHandler) ListPromptTemplates(c *gin.Context) {
+	var req dto.ListPromptTemplatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	templates, err := h.store.Querier.ListPromptTemplates(c, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.PromptTemplateResponse{}
+	for _, t := range templates {
+		rsp = append(rsp, mapPromptTemplateToDTO(t))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *PromptHandler) GetPromptTemplate(c *gin.Context) {
+	template, ok := h.loadPromptTemplate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, mapPromptTemplateToDTO(template))
+}
+
+// CreatePromptTemplate stores a new version of the named template. Versions
+// are never edited in place, so every stored answer can be traced back to
+// the exact prompt that produced it.
+func (h *PromptHandler) CreatePromptTemplate(c *gin.Context) {
+	var req dto.CreatePromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if !departmentSlug.MatchString(name) {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("template name must be lowercase letters, digits and dashes")))
+		return
+	}
+	if err := ai.ValidatePromptTemplate(req.SystemPrompt, req.ContextPrompt); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	template, err := h.store.Querier.CreatePromptTemplate(c, db.CreatePromptTemplateParams{
+		Name:          name,
+		SystemPrompt:  req.SystemPrompt,
+		ContextPrompt: req.ContextPrompt,
+		CreatedBy:     pgtype.UUID{Bytes: payload.UserExternalID, Valid: true},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	if req.Activate {
+		template, err = h.store.ActivatePromptTemplateTx(c, template.TemplateExternalID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+			return
+		}
+	}
+	c.JSON(http.StatusCreated, mapPromptTemplateToDTO(template))
+}
+
+// ActivatePromptTemplate makes the version live for its name. Activating an
+// older version is how a change is rolled back.
+func (h *PromptHandler) ActivatePromptTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid template id")))
+		return
+	}
+
+	template, err := h.store.ActivatePromptTemplateTx(c, templateID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("template not found")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, mapPromptTemplateToDTO(template))
+}
+
+// DeletePromptTemplate removes a version that is neither live nor referenced
+// by any message.
+func (h *PromptHandler) DeletePromptTemplate(c *gin.Context) {
+	template, ok := h.loadPromptTemplate(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.store.Querier.DeletePromptTemplate(c, template.TemplateExternalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusConflict, util.ErrorResponse(errors.New("template is active or has been used to answer messages")))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "template deleted"})
+}
+
+// PreviewPromptTemplate renders a template against a sample question with
+// live retrieval, and asks the model for an answer when generate is set.
+func (h *PromptHandler) PreviewPromptTemplate(c *gin.Context) {
+	var req dto.PreviewPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	var template ai.PromptTemplate
+	switch {
+	case req.TemplateID != "":
+		templateID, err := uuid.Parse(req.TemplateID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid template id")))
+			return
+		}
+		stored, err := h.store.Querier.GetPromptTemplate(c, templateID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("template not found")))
+				return
+			}
+			c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+			return
+		}
+		template = ai.PromptTemplateFromDB(stored)
+	case req.SystemPrompt != "" || req.ContextPrompt != "":
+		template = ai.PromptTemplate{System: req.SystemPrompt, Context: req.ContextPrompt}
+	default:
+		template = ai.ActivePrompt(c, h.store, ai.DefaultPromptName)
+	}
+
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	preview, err := h.previewer.Preview(c, ai.PromptPreviewRequest{
+		Template:       template,
+		Question:       req.Question,
+		Language:       req.Language,
+		Department:     req.Department,
+		UserExternalID: payload.UserExternalID,
+		Generate:       req.Generate,
+	})
+	if err != nil {
+		if errors.Is(err, ai.ErrCircuitOpen) {
+			c.JSON(http.StatusServiceUnavailable, util.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := dto.PreviewPromptResponse{
+		Messages:     []dto.PromptMessage{},
+		Sources:      mapStreamCitationsToDTO(ai.Citations(preview.Sources)),
+		Answer:       preview.Answer,
+		Tokens:       preview.Packing.Tokens,
+		Budget:       preview.Packing.Budget,
+		AnswerTokens: preview.Packing.AnswerTokens,
+		Candidates:   preview.Packing.Candidates,
+		Duplicates:   preview.Packing.Duplicates,
+	}
+	for _, m := range preview.Messages {
+		rsp.Messages = append(rsp.Messages, dto.PromptMessage{Role: m.Role, Content: m.Content})
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *PromptHandler) loadPromptTemplate(c *gin.Context) (db.PromptTemplate, bool) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid template id")))
+		return db.PromptTemplate{}, false
+	}
+	template, err := h.store.Querier.GetPromptTemplate(c, templateID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("template not found")))
+			return db.PromptTemplate{}, false
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return db.PromptTemplate{}, false
+	}
+	return template, true
+}
+
+func mapPromptTemplateToDTO(t db.PromptTemplate) dto.PromptTemplateResponse {
+	rsp := dto.PromptTemplateResponse{
+		TemplateExternalID: t.TemplateExternalID.String(),
+		Name:               t.Name,
+		Version:            t.Version,
+		SystemPrompt:       t.SystemPrompt,
+		ContextPrompt:      t.ContextPrompt,
+		IsActive:           t.IsActive,
+		CreatedAt:          t.CreatedAt.Time,
+	}
+	if t.CreatedBy.Valid {
+		rsp.CreatedBy = uuid.UUID(t.CreatedBy.Bytes).String()
+	}
+	return rsp
+}
+
+func mapStreamCitationsToDTO(citations []ws.Citation) []dto.Citation {
+	rsp := make([]dto.Citation, 0, len(citations))
+	for _, c := range citations {
+		rsp = append(rsp, dto.Citation(c))
+	}
+	return rsp
+}
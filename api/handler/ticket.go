@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zahra-pzk/Chatbot_Project3/api/dto"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/token"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+// TicketHandler lists the follow-up tickets the bot opens for chats it could
+// not finish, and lets staff mark them resolved.
+type TicketHandler struct {
+	store      *db.SQLStore
+	tokenMaker token.Maker
+	config     util.Config
+}
+
+func NewTicketHandler(store *db.SQLStore, tokenMaker token.Maker, config util.Config) *TicketHandler {
+	return &TicketHandler{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+func (h *TicketHandler) ListTickets(c *gin.Context) {
+	var req dto.ListTicketsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	tickets, err := h.store.Querier.ListFollowUpTickets(c, db.ListFollowUpTicketsParams{
+		Status:     pgtype.Text{String: req.Status, Valid: req.Status != ""},
+		Department: pgtype.Text{String: req.Department, Valid: req.Department != ""},
+		RowLimit:   req.Limit,
+		RowOffset:  req.Offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+
+	rsp := []dto.TicketResponse{}
+	for _, t := range tickets {
+		rsp = append(rsp, mapTicketToDTO(t))
+	}
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (h *TicketHandler) ResolveTicket(c *gin.Context) {
+	payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	ticketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.ErrorResponse(errors.New("invalid ticket id")))
+		return
+	}
+
+	ticket, err := h.store.Querier.ResolveFollowUpTicket(c, db.ResolveFollowUpTicketParams{
+		TicketExternalID: ticketID,
+		ResolvedBy:       pgtype.UUID{Bytes: payload.UserExternalID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, util.ErrorResponse(errors.New("ticket not found")))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, mapTicketToDTO(ticket))
+}
+
+func mapTicketToDTO(t db.FollowUpTicket) dto.TicketResponse {
+	rsp := dto.TicketResponse{
+		TicketExternalID: t.TicketExternalID.String(),
+		ChatExternalID:   t.ChatExternalID.String(),
+		UserExternalID:   t.UserExternalID.String(),
+		Department:       t.Department.String,
+		Subject:          t.Subject,
+		Details:          t.Details.String,
+		Status:           t.Status,
+		CreatedAt:        t.CreatedAt.Time,
+	}
+	if t.ResolvedBy.Valid {
+		rsp.ResolvedBy = uuid.UUID(t.ResolvedBy.Bytes).String()
+	}
+	if t.ResolvedAt.Valid {
+		rsp.ResolvedAt = &t.ResolvedAt.Time
+	}
+	return rsp
+}
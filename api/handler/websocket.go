@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -80,4 +83,66 @@ func (h *WebSocketHandler) ServeWs(c *gin.Context) {
 
 	go client.WritePump()
 	go client.ReadPump()
-}
\ No newline at end of file
+}
+
+// ServeAdminChatsWs streams chat updates to admin dashboards: a snapshot of
+// the most recent chats on connect, then every change the hub publishes on
+// ws.AdminChannelID, including chats the bot handed off to a person.
+func (h *WebSocketHandler) ServeAdminChatsWs(c *gin.Context) {
+	tokenString := c.Query("token")
+	if fields := strings.Fields(c.GetHeader("Authorization")); len(fields) == 2 && strings.EqualFold(fields[0], "bearer") {
+		tokenString = fields[1]
+	}
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, util.ErrorResponse(errors.New("token not provided")))
+		return
+	}
+	payload, err := h.tokenMaker.VerifyToken(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, util.ErrorResponse(err))
+		return
+	}
+	if payload.Role != string(db.RoleTypeAdmin) && payload.Role != string(db.RoleTypeSuperadmin) {
+		c.JSON(http.StatusForbidden, util.ErrorResponse(errors.New("admins only")))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	chats, err := h.store.Querier.ListChats(c, db.ListChatsParams{Limit: 100, Offset: 0})
+	if err != nil {
+		log.Println("cannot list chats for admin feed:", err)
+	}
+	items := []ws.AdminChatItem{}
+	for _, chat := range chats {
+		item := ws.NewAdminChatItem(chat)
+		if chat.Status == string(db.ChatStatusTypeWaiting) {
+			if handoff, err := h.store.Querier.GetLatestHandoffByChat(c, chat.ChatExternalID); err == nil {
+				item.HandoffReason = handoff.Reason
+				item.HandoffDetail = handoff.Detail.String
+			}
+		}
+		items = append(items, item)
+	}
+	snapshot, _ := json.Marshal(items)
+
+	client := &ws.Client{
+		Hub:            h.hub,
+		Conn:           conn,
+		Send:           make(chan []byte, 256),
+		Store:          h.store,
+		ChatExternalID: ws.AdminChannelID,
+		UserExternalID: payload.UserExternalID,
+		Role:           payload.Role,
+	}
+	client.Send <- snapshot
+
+	h.hub.Register <- client
+
+	go client.WritePump()
+	go client.DrainPump()
+}
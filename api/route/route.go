@@ -11,6 +11,8 @@ import (
 	"github.com/zahra-pzk/Chatbot_Project3/token"
 	"github.com/zahra-pzk/Chatbot_Project3/util"
 	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	"github.com/zahra-pzk/Chatbot_Project3/storage"
 )
 
 type Server struct {
@@ -19,9 +21,14 @@ type Server struct {
 	tokenMaker token.Maker
 	router     *gin.Engine
 	hub        *ws.Hub
+	files      storage.Storage
+	documents  *ai.DocumentProcessor
+	prompts    *ai.PromptPreviewer
+	embeddings *ai.EmbeddingService
+	knowledge  *ai.KnowledgeBase
 }
 
-func NewServer(config util.Config, store *db.SQLStore, hub *ws.Hub) (*Server, error) {
+func NewServer(config util.Config, store *db.SQLStore, hub *ws.Hub, files storage.Storage, documents *ai.DocumentProcessor, prompts *ai.PromptPreviewer, embeddings *ai.EmbeddingService, knowledge *ai.KnowledgeBase) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
@@ -31,6 +38,11 @@ func NewServer(config util.Config, store *db.SQLStore, hub *ws.Hub) (*Server, er
 		store:      store,
 		tokenMaker: tokenMaker,
 		hub:        hub,
+		files:      files,
+		documents:  documents,
+		prompts:    prompts,
+		embeddings: embeddings,
+		knowledge:  knowledge,
 	}
 	server.setupRouter()
 	return server, nil
@@ -51,6 +63,16 @@ func (server *Server) setupRouter() {
 	chatHandler := handler.NewChatHandler(server.store, server.tokenMaker, server.config)
 	websocketHandler := handler.NewWebSocketHandler(server.store, server.tokenMaker, server.config, server.hub)
 	messageHandler := handler.NewMessageHandler(server.store, server.tokenMaker, server.config)
+	jobHandler := handler.NewJobHandler(server.store, server.tokenMaker, server.config)
+	documentHandler := handler.NewDocumentHandler(server.store, server.tokenMaker, server.config, server.files, server.documents)
+	departmentHandler := handler.NewDepartmentHandler(server.store, server.tokenMaker, server.config)
+	handoffHandler := handler.NewHandoffHandler(server.store, server.tokenMaker, server.config)
+	promptHandler := handler.NewPromptHandler(server.store, server.tokenMaker, server.config, server.prompts)
+	embeddingHandler := handler.NewEmbeddingHandler(server.store, server.tokenMaker, server.config, server.embeddings)
+	knowledgeHandler := handler.NewKnowledgeHandler(server.store, server.tokenMaker, server.config, server.knowledge)
+	feedbackHandler := handler.NewFeedbackHandler(server.store, server.tokenMaker, server.config)
+	personaHandler := handler.NewPersonaHandler(server.store, server.tokenMaker, server.config)
+	ticketHandler := handler.NewTicketHandler(server.store, server.tokenMaker, server.config)
 
 	router.POST("/users", authHandler.CreateUser)
 	router.POST("/users/guest", authHandler.CreateGuest)
@@ -60,6 +82,7 @@ func (server *Server) setupRouter() {
 	router.POST("/tokens/renew_access", authHandler.RenewAccessToken)
 
 	router.GET("/ws/chat/:id", websocketHandler.ServeWs)
+	router.GET("/ws/admin/chats", websocketHandler.ServeAdminChatsWs)
 	router.POST("/chats/start", middleware.OptionalAuthMiddleware(server.tokenMaker), chatHandler.StartChat)
 
 	authRoutes := router.Group("/").Use(middleware.AuthMiddleware(server.tokenMaker))
@@ -76,6 +99,43 @@ func (server *Server) setupRouter() {
 	authRoutes.GET("/chats/:id/messages", messageHandler.ListMessages)
 	authRoutes.PATCH("/messages/:id", messageHandler.EditMessage)
 	authRoutes.DELETE("/messages/:id", messageHandler.DeleteMessage)
+	authRoutes.POST("/messages/:id/reactions", messageHandler.AddReaction)
+	authRoutes.DELETE("/messages/:id/reactions", messageHandler.RemoveReaction)
+
+	adminRoutes := router.Group("/admin").Use(
+		middleware.AuthMiddleware(server.tokenMaker),
+		middleware.RoleMiddleware(db.RoleTypeAdmin, db.RoleTypeSuperadmin),
+	)
+	adminRoutes.POST("/documents", documentHandler.UploadDocument)
+	adminRoutes.GET("/documents", documentHandler.ListDocuments)
+	adminRoutes.POST("/documents/:id/reprocess", documentHandler.ReprocessDocument)
+	adminRoutes.DELETE("/documents/:id", documentHandler.DeleteDocument)
+	adminRoutes.GET("/jobs", jobHandler.ListJobs)
+	adminRoutes.POST("/jobs/:id/retry", jobHandler.RetryJob)
+	adminRoutes.GET("/departments", departmentHandler.ListDepartments)
+	adminRoutes.PUT("/departments/:slug", departmentHandler.UpsertDepartment)
+	adminRoutes.DELETE("/departments/:slug", departmentHandler.DeleteDepartment)
+	adminRoutes.GET("/queue", departmentHandler.ListQueue)
+	adminRoutes.PATCH("/chats/:id/department", departmentHandler.SetChatDepartment)
+	adminRoutes.GET("/chats/:id/handoffs", handoffHandler.ListHandoffs)
+	adminRoutes.GET("/prompts", promptHandler.ListPromptTemplates)
+	adminRoutes.POST("/prompts", promptHandler.CreatePromptTemplate)
+	adminRoutes.POST("/prompts/preview", promptHandler.PreviewPromptTemplate)
+	adminRoutes.GET("/prompts/:id", promptHandler.GetPromptTemplate)
+	adminRoutes.POST("/prompts/:id/activate", promptHandler.ActivatePromptTemplate)
+	adminRoutes.DELETE("/prompts/:id", promptHandler.DeletePromptTemplate)
+	adminRoutes.GET("/embeddings/stats", embeddingHandler.GetEmbeddingStats)
+	adminRoutes.POST("/chats/:id/knowledge", knowledgeHandler.PromoteKnowledge)
+	adminRoutes.GET("/knowledge", knowledgeHandler.ListKnowledge)
+	adminRoutes.DELETE("/knowledge/:id", knowledgeHandler.DeleteKnowledge)
+	adminRoutes.GET("/feedback/chunks", feedbackHandler.ListDownvotedChunks)
+	adminRoutes.GET("/feedback/sources", feedbackHandler.ListDownvotedSources)
+	adminRoutes.GET("/personas", personaHandler.ListPersonas)
+	adminRoutes.PUT("/personas/:slug", personaHandler.UpsertPersona)
+	adminRoutes.DELETE("/personas/:slug", personaHandler.DeletePersona)
+	adminRoutes.PATCH("/chats/:id/persona", personaHandler.SetChatPersona)
+	adminRoutes.GET("/tickets", ticketHandler.ListTickets)
+	adminRoutes.POST("/tickets/:id/resolve", ticketHandler.ResolveTicket)
 
 	superAdminRoutes := router.Group("/").Use(middleware.RoleMiddleware(db.RoleTypeSuperadmin))
 	superAdminRoutes.DELETE("/chats/:id", chatHandler.DeleteChat)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
 )
 
@@ -34,10 +35,11 @@ type IncomingMessage struct {
 }
 
 type OutgoingMessage struct {
-	Content          string    `json:"content"`
-	SenderExternalID uuid.UUID `json:"sender_external_id"`
-	CreatedAt        string    `json:"created_at"`
-	IsSystem         bool      `json:"is_system"`
+	MessageExternalID uuid.UUID `json:"message_external_id"`
+	Content           string    `json:"content"`
+	SenderExternalID  uuid.UUID `json:"sender_external_id"`
+	CreatedAt         string    `json:"created_at"`
+	IsSystem          bool      `json:"is_system"`
 }
 
 func (c *Client) ReadPump() {
@@ -75,6 +77,9 @@ func (c *Client) ReadPump() {
 					Column2:        string(db.ChatStatusTypeOpen),
 				})
 			}
+			if chat.Status == string(db.ChatStatusTypeWaiting) && c.Role != "system" {
+				c.pickUp()
+			}
 		}
 
 		arg := db.CreateMessageParams{
@@ -92,10 +97,11 @@ func (c *Client) ReadPump() {
 		}
 
 		outMsg := OutgoingMessage{
-			Content:          msg.Content,
-			SenderExternalID: msg.SenderExternalID,
-			CreatedAt:        msg.CreatedAt.Time.Format(time.RFC3339),
-			IsSystem:         msg.IsSystemMessage,
+			MessageExternalID: msg.MessageExternalID,
+			Content:           msg.Content,
+			SenderExternalID:  msg.SenderExternalID,
+			CreatedAt:         msg.CreatedAt.Time.Format(time.RFC3339),
+			IsSystem:          msg.IsSystemMessage,
 		}
 
 		jsonBytes, _ := json.Marshal(outMsg)
@@ -104,7 +110,60 @@ func (c *Client) ReadPump() {
 			ChatExternalID: c.ChatExternalID,
 			Data:           jsonBytes,
 		}
+		c.Hub.Publish <- MessageEvent{
+			ChatExternalID:    msg.ChatExternalID,
+			MessageExternalID: msg.MessageExternalID,
+			SenderExternalID:  msg.SenderExternalID,
+			SenderRole:        c.Role,
+			Content:           msg.Content,
+			IsSystemMessage:   msg.IsSystemMessage,
+			IsAdminMessage:    msg.IsAdminMessage,
+		}
+	}
+}
+
+// DrainPump is the read side of feed-only connections such as the admin
+// feed: it keeps the connection alive and unregisters the client once it
+// goes away, but never stores what the peer sends.
+func (c *Client) DrainPump() {
+	defer func() {
+		c.Hub.Unregister <- c
+		c.Conn.Close()
+	}()
+	c.Conn.SetReadLimit(maxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	for {
+		if _, _, err := c.Conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// pickUp hands a chat the bot gave up on to the admin who answered it first
+// and tells the other dashboards it is taken.
+func (c *Client) pickUp() {
+	ctx := context.Background()
+	_, err := c.Store.Querier.AssignedAdminToChat(ctx, db.AssignedAdminToChatParams{
+		ChatExternalID:  c.ChatExternalID,
+		AdminExternalID: pgtype.UUID{Bytes: c.UserExternalID, Valid: true},
+	})
+	if err != nil {
+		log.Printf("cannot assign chat %s: %v", c.ChatExternalID, err)
+		return
+	}
+	chat, err := c.Store.Querier.UpdateChatStatus(ctx, db.UpdateChatStatusParams{
+		ChatExternalID: c.ChatExternalID,
+		Column2:        string(db.ChatStatusTypeOpen),
+	})
+	if err != nil {
+		log.Printf("cannot reopen chat %s: %v", c.ChatExternalID, err)
+		return
 	}
+	c.Hub.NotifyAdmins(NewAdminChatItem(chat))
 }
 
 func (c *Client) WritePump() {
@@ -0,0 +1,42 @@
+package ws
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// eventBuffer is how many events a subscriber may fall behind before the
+// hub starts dropping them for it; the hub never waits on a subscriber.
+const eventBuffer = 256
+
+// MessageEvent is published for every message stored in a chat, so that
+// in-process services such as the support bot can react to it without a
+// WebSocket connection of their own.
+type MessageEvent struct {
+	ChatExternalID    uuid.UUID
+	MessageExternalID uuid.UUID
+	SenderExternalID  uuid.UUID
+	SenderRole        string
+	Content           string
+	IsSystemMessage   bool
+	IsAdminMessage    bool
+}
+
+// Subscribe returns a channel receiving every MessageEvent published from
+// now on. Run must be going.
+func (h *Hub) Subscribe() <-chan MessageEvent {
+	sub := make(chan MessageEvent, eventBuffer)
+	h.subscribe <- sub
+	return sub
+}
+
+func (h *Hub) deliver(event MessageEvent) {
+	for _, sub := range h.subscribers {
+		select {
+		case sub <- event:
+		default:
+			log.Printf("subscriber is %d events behind, dropping message %s", eventBuffer, event.MessageExternalID)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+const (
+	FrameTypeDelta     = "delta"
+	FrameTypeCompleted = "completed"
+	FrameTypeFailed    = "failed"
+	FrameTypeEnded     = "ended"
+)
+
+// Citation is a source of a bot answer as clients see it. It mirrors
+// dto.Citation so the hub does not depend on the HTTP layer.
+type Citation struct {
+	ChunkExternalID string `json:"chunk_external_id,omitempty"`
+	SourceID        string `json:"source_id,omitempty"`
+	SourceFilename  string `json:"source_filename"`
+	SourcePage      int32  `json:"source_page,omitempty"`
+	Position        int32  `json:"position"`
+}
+
+// StreamFrame is sent to a chat room while a bot answer is being generated.
+// Every frame of one answer shares the same StreamID; the completed frame
+// carries the full text, the ID of the stored message and the sources the
+// answer was built from. An ended frame means the bot handed the chat to a
+// person instead, and any text streamed so far should be dropped.
+type StreamFrame struct {
+	Type              string     `json:"type"`
+	StreamID          string     `json:"stream_id"`
+	ChatExternalID    uuid.UUID  `json:"chat_external_id"`
+	SenderExternalID  uuid.UUID  `json:"sender_external_id"`
+	SenderName        string     `json:"sender_name,omitempty"`
+	SenderAvatarURL   string     `json:"sender_avatar_url,omitempty"`
+	Delta             string     `json:"delta,omitempty"`
+	Content           string     `json:"content,omitempty"`
+	MessageExternalID *uuid.UUID `json:"message_external_id,omitempty"`
+	CreatedAt         string     `json:"created_at,omitempty"`
+	Citations         []Citation `json:"citations,omitempty"`
+	Error             string     `json:"error,omitempty"`
+}
+
+// AdminChannelID is the hub room admin dashboards subscribe to. No chat has
+// the nil UUID, so it never collides with a chat room.
+var AdminChannelID = uuid.Nil
+
+// AdminChatItem is one entry of the admin feed. The feed always sends a JSON
+// array of them: the initial snapshot and every later update alike. Handoff
+// fields are set when the bot gave the chat up to a person.
+type AdminChatItem struct {
+	ChatExternalID string `json:"chat_external_id"`
+	UserExternalID string `json:"user_external_id"`
+	Status         string `json:"status"`
+	Department     string `json:"department,omitempty"`
+	UpdatedAt      string `json:"updated_at"`
+	HandoffReason  string `json:"handoff_reason,omitempty"`
+	HandoffDetail  string `json:"handoff_detail,omitempty"`
+}
+
+func NewAdminChatItem(chat db.Chat) AdminChatItem {
+	return AdminChatItem{
+		ChatExternalID: chat.ChatExternalID.String(),
+		UserExternalID: chat.UserExternalID.String(),
+		Status:         chat.Status,
+		Department:     chat.Department.String,
+		UpdatedAt:      chat.UpdatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+// NotifyAdmins pushes items to every connected admin dashboard.
+func (h *Hub) NotifyAdmins(items ...AdminChatItem) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return
+	}
+	h.Broadcast <- BroadcastMessage{
+		ChatExternalID: AdminChannelID,
+		Data:           data,
+	}
+}
@@ -12,6 +12,10 @@ type Hub struct {
 	Broadcast  chan BroadcastMessage
 	Register   chan *Client
 	Unregister chan *Client
+	Publish    chan MessageEvent
+
+	subscribe   chan chan MessageEvent
+	subscribers []chan MessageEvent
 }
 
 func NewHub() *Hub {
@@ -21,6 +25,8 @@ func NewHub() *Hub {
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
 		Clients:    make(map[string]map[*Client]bool),
+		Publish:    make(chan MessageEvent),
+		subscribe:  make(chan chan MessageEvent),
 	}
 }
 
@@ -53,6 +59,10 @@ func (h *Hub) Run() {
 					delete(h.Clients[message.ChatExternalID.String()], client)
 				}
 			}
+		case sub := <-h.subscribe:
+			h.subscribers = append(h.subscribers, sub)
+		case event := <-h.Publish:
+			h.deliver(event)
 		}
 	}
 }
\ No newline at end of file
@@ -0,0 +1,130 @@
+// Command rageval runs a golden set of questions through the bot's retrieval
+// and completion and reports how well it did, optionally against an earlier
+// report:
+//
+//	go run ./cmd/rageval -set golden.jsonl -out after.json -baseline before.json
+//
+// Without -docs it searches the configured vector store. With -docs it
+// ingests the given files into memory first, which together with
+// -provider fake needs neither a database nor an AI backend.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zahra-pzk/Chatbot_Project3/ai"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+func main() {
+	var (
+		configPath    = flag.String("config", ".", "directory holding app.env")
+		setPath       = flag.String("set", "", "golden set, .jsonl, .json or .yaml (required)")
+		docs          = flag.String("docs", "", "comma-separated files to ingest into an in-memory store instead of using the configured one")
+		provider      = flag.String("provider", "", "AI provider for completions and embeddings, overriding AI_PROVIDER (openai, ollama, fake)")
+		topK          = flag.Int("k", 0, "chunks to retrieve per question; 0 uses RETRIEVAL_TOP_K")
+		promptName    = flag.String("prompt", ai.DefaultPromptName, "prompt template to use when a database is configured")
+		retrievalOnly = flag.Bool("retrieval-only", false, "score retrieval only, without generating answers")
+		name          = flag.String("name", "", "name of this run in the report")
+		outPath       = flag.String("out", "", "write the JSON report here")
+		baselinePath  = flag.String("baseline", "", "earlier JSON report to compare against")
+	)
+	flag.Parse()
+	if *setPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config, err := util.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+	if *provider != "" {
+		config.AIProvider = *provider
+		config.AIEmbeddingProvider = *provider
+	}
+	ctx := context.Background()
+
+	cases, err := ai.LoadEvalSet(*setPath)
+	if err != nil {
+		log.Fatal("cannot load golden set:", err)
+	}
+
+	embedder, err := ai.NewEmbeddingProvider(config)
+	if err != nil {
+		log.Fatal("cannot create embedding provider:", err)
+	}
+
+	var (
+		vectorStore ai.VectorStore
+		template    = ai.BuiltinPromptTemplate()
+	)
+	if *docs != "" {
+		vectorStore = ai.NewMemoryVectorStore()
+		for _, path := range strings.Split(*docs, ",") {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+			report, err := ai.CreateVectorStore(ctx, config, embedder, vectorStore, path, ai.IngestOptions{})
+			if err != nil {
+				log.Fatalf("cannot ingest %s: %v", path, err)
+			}
+			log.Printf("%s ingested: %s", path, report)
+		}
+	} else {
+		pool, err := pgxpool.New(ctx, config.DBSource)
+		if err != nil {
+			log.Fatal("cannot connect to db:", err)
+		}
+		defer pool.Close()
+		vectorStore, err = ai.NewVectorStore(ctx, config, pool)
+		if err != nil {
+			log.Fatal("cannot create vector store:", err)
+		}
+		template = ai.ActivePrompt(ctx, db.NewStore(pool), *promptName)
+	}
+
+	var completer ai.CompletionProvider
+	if !*retrievalOnly {
+		completer, err = ai.NewCompletionProvider(config)
+		if err != nil {
+			log.Fatal("cannot create completion provider:", err)
+		}
+	}
+
+	runName := *name
+	if runName == "" {
+		runName = time.Now().Format("2006-01-02 15:04")
+	}
+	evaluator := ai.NewEvaluator(config, vectorStore, embedder, completer)
+	report := evaluator.Run(ctx, cases, ai.EvalOptions{
+		Name:     runName,
+		TopK:     *topK,
+		Template: template,
+	})
+
+	ai.PrintEvalSummary(os.Stdout, report)
+	if *outPath != "" {
+		if err := ai.WriteEvalReport(*outPath, report); err != nil {
+			log.Fatal("cannot write report:", err)
+		}
+		fmt.Println("Report written to", *outPath)
+	}
+	if *baselinePath != "" {
+		baseline, err := ai.LoadEvalReport(*baselinePath)
+		if err != nil {
+			log.Fatal("cannot load baseline:", err)
+		}
+		fmt.Println()
+		ai.PrintEvalComparison(os.Stdout, baseline, report)
+	}
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS vector;
+
+ALTER TABLE chunks DROP COLUMN IF EXISTS embedding_vector;
+ALTER TABLE chunks ADD COLUMN embedding_vector vector;
+
+CREATE INDEX IF NOT EXISTS idx_chunks_embedding_hnsw_1536
+ON chunks USING hnsw ((embedding_vector::vector(1536)) vector_cosine_ops)
+WHERE vector_dims(embedding_vector) = 1536;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chunks_embedding_hnsw_1536;
+ALTER TABLE chunks DROP COLUMN IF EXISTS embedding_vector;
+ALTER TABLE chunks ADD COLUMN embedding_vector BYTEA;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS message_citations (
+    citation_id          BIGSERIAL,
+    citation_external_id UUID                        PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_external_id  UUID                        NOT NULL,
+    chunk_external_id    UUID,
+    source_id            UUID,
+    source_filename      TEXT,
+    source_page          INT,
+    position             INT                         NOT NULL,
+    created_at           TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_citations_message FOREIGN KEY (message_external_id)
+        REFERENCES messages (message_external_id) ON DELETE CASCADE,
+    CONSTRAINT fk_citations_chunk FOREIGN KEY (chunk_external_id)
+        REFERENCES chunks (chunk_external_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_citations_message ON message_citations(message_external_id);
+CREATE INDEX IF NOT EXISTS idx_citations_chunk ON message_citations(chunk_external_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_citations_chunk;
+DROP INDEX IF EXISTS idx_citations_message;
+DROP TABLE IF EXISTS message_citations;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE source_files ADD COLUMN IF NOT EXISTS error_message TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_source_files_status ON source_files(status);
+CREATE INDEX IF NOT EXISTS idx_chunks_source_id ON chunks(source_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chunks_source_id;
+DROP INDEX IF EXISTS idx_source_files_status;
+ALTER TABLE source_files DROP COLUMN IF EXISTS error_message;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+    job_id           BIGSERIAL,
+    job_external_id  UUID                     PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind             TEXT                     NOT NULL,
+    payload          JSONB                    NOT NULL DEFAULT '{}',
+    status           TEXT                     NOT NULL DEFAULT 'queued',
+    attempts         INT                      NOT NULL DEFAULT 0,
+    max_attempts     INT                      NOT NULL DEFAULT 5,
+    run_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    locked_at        TIMESTAMP WITH TIME ZONE,
+    locked_by        TEXT,
+    last_error       TEXT,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    finished_at      TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_jobs_status CHECK (status IN ('queued', 'running', 'succeeded', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queued ON jobs(run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_jobs_status_created_at ON jobs(status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_jobs_status_created_at;
+DROP INDEX IF EXISTS idx_jobs_queued;
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
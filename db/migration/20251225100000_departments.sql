@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS departments (
+    department_id   BIGSERIAL,
+    slug            TEXT PRIMARY KEY,
+    name            TEXT NOT NULL,
+    keywords        TEXT[] NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS department TEXT;
+ALTER TABLE source_files ADD COLUMN IF NOT EXISTS department TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_chats_department_status ON chats(department, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chats_department_status;
+ALTER TABLE source_files DROP COLUMN IF EXISTS department;
+ALTER TABLE chats DROP COLUMN IF EXISTS department;
+DROP TABLE IF EXISTS departments;
+-- +goose StatementEnd
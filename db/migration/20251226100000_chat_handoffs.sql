@@ -0,0 +1,31 @@
+-- +goose NO TRANSACTION
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block.
+
+-- +goose Up
+ALTER TYPE chat_status_type ADD VALUE IF NOT EXISTS 'waiting';
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chat_handoffs (
+    handoff_id           BIGSERIAL,
+    handoff_external_id  UUID             PRIMARY KEY DEFAULT gen_random_uuid(),
+    chat_external_id     UUID             NOT NULL,
+    message_external_id  UUID,
+    reason               TEXT             NOT NULL,
+    detail               TEXT,
+    similarity           DOUBLE PRECISION,
+    created_at           TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    CONSTRAINT fk_handoffs_chat FOREIGN KEY (chat_external_id)
+        REFERENCES chats (chat_external_id) ON DELETE CASCADE,
+    CONSTRAINT fk_handoffs_message FOREIGN KEY (message_external_id)
+        REFERENCES messages (message_external_id) ON DELETE SET NULL
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_handoffs_chat ON chat_handoffs(chat_external_id, created_at);
+
+-- +goose Down
+-- Postgres cannot drop an enum value, so 'waiting' stays; chats in it go back
+-- to pending.
+UPDATE chats SET status = 'pending' WHERE status = 'waiting';
+DROP INDEX IF EXISTS idx_handoffs_chat;
+DROP TABLE IF EXISTS chat_handoffs;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS prompt_templates (
+    template_id           BIGSERIAL,
+    template_external_id  UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                  TEXT         NOT NULL,
+    version               INTEGER      NOT NULL,
+    system_prompt         TEXT         NOT NULL,
+    context_prompt        TEXT         NOT NULL,
+    is_active             BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_by            UUID,
+    created_at            TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    CONSTRAINT uq_prompt_templates_version UNIQUE (name, version)
+);
+
+-- At most one live version per template name.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_active
+    ON prompt_templates(name) WHERE is_active;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS prompt_template_id UUID
+    REFERENCES prompt_templates (template_external_id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE messages DROP COLUMN IF EXISTS prompt_template_id;
+DROP INDEX IF EXISTS idx_prompt_templates_active;
+DROP TABLE IF EXISTS prompt_templates;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    content_hash  TEXT                NOT NULL,
+    model         TEXT                NOT NULL,
+    embedding     DOUBLE PRECISION[]  NOT NULL,
+    hits          BIGINT              NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ         NOT NULL DEFAULT now(),
+    last_used_at  TIMESTAMPTZ         NOT NULL DEFAULT now(),
+    PRIMARY KEY (content_hash, model)
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_cache_last_used ON embedding_cache(last_used_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_embedding_cache_last_used;
+DROP TABLE IF EXISTS embedding_cache;
+-- +goose StatementEnd
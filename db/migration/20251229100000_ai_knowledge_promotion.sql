@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE ai_knowledge
+    ADD COLUMN IF NOT EXISTS chat_external_id    UUID,
+    ADD COLUMN IF NOT EXISTS question_message_id UUID,
+    ADD COLUMN IF NOT EXISTS answer_message_id   UUID,
+    ADD CONSTRAINT fk_knowledge_chat FOREIGN KEY (chat_external_id)
+        REFERENCES chats (chat_external_id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_knowledge_question FOREIGN KEY (question_message_id)
+        REFERENCES messages (message_external_id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_knowledge_answer FOREIGN KEY (answer_message_id)
+        REFERENCES messages (message_external_id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_knowledge_answer_message
+ON ai_knowledge(answer_message_id) WHERE answer_message_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_ai_knowledge_answer_message;
+ALTER TABLE ai_knowledge
+    DROP CONSTRAINT IF EXISTS fk_knowledge_answer,
+    DROP CONSTRAINT IF EXISTS fk_knowledge_question,
+    DROP CONSTRAINT IF EXISTS fk_knowledge_chat,
+    DROP COLUMN IF EXISTS answer_message_id,
+    DROP COLUMN IF EXISTS question_message_id,
+    DROP COLUMN IF EXISTS chat_external_id;
+-- +goose StatementEnd
@@ -0,0 +1,142 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chunk_feedback (
+    chunk_external_id  UUID        PRIMARY KEY,
+    feedback_score     BIGINT      NOT NULL DEFAULT 0,
+    feedback_weight    BIGINT      NOT NULL DEFAULT 0,
+    rated_answers      INT         NOT NULL DEFAULT 0,
+    upvoted_answers    INT         NOT NULL DEFAULT 0,
+    downvoted_answers  INT         NOT NULL DEFAULT 0,
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_chunk_feedback_chunk FOREIGN KEY (chunk_external_id)
+        REFERENCES chunks (chunk_external_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_chunk_feedback_downvoted ON chunk_feedback(downvoted_answers DESC);
+
+-- Every answer citing a chunk counts once, with the net score of its
+-- reactions: feedback_score sums them, feedback_weight sums their size, so
+-- feedback_score / feedback_weight says how one-sided the reactions are.
+-- Only the chunks cited by p_message_uuid are recomputed, from the citations
+-- of those chunks alone. Reactions outside the set the API accepts (see
+-- dto.ReactionRequest) are not feedback.
+CREATE OR REPLACE FUNCTION update_chunk_feedback(p_message_uuid UUID) RETURNS VOID
+LANGUAGE plpgsql AS $$
+DECLARE
+  cited UUID[];
+BEGIN
+  SELECT array_agg(DISTINCT chunk_external_id) INTO cited
+  FROM message_citations
+  WHERE message_external_id = p_message_uuid
+    AND chunk_external_id IS NOT NULL;
+  IF cited IS NULL THEN
+    RETURN;
+  END IF;
+
+  INSERT INTO chunk_feedback (
+    chunk_external_id, feedback_score, feedback_weight,
+    rated_answers, upvoted_answers, downvoted_answers, updated_at
+  )
+  SELECT mc.chunk_external_id,
+         COALESCE(SUM(answers.net), 0),
+         COALESCE(SUM(ABS(answers.net)), 0),
+         COUNT(answers.net),
+         COUNT(*) FILTER (WHERE answers.net > 0),
+         COUNT(*) FILTER (WHERE answers.net < 0),
+         NOW()
+  FROM (
+    SELECT DISTINCT message_external_id, chunk_external_id
+    FROM message_citations
+    WHERE chunk_external_id = ANY(cited)
+  ) mc
+  LEFT JOIN LATERAL (
+    SELECT SUM(mr.score) AS net
+    FROM message_reactions mr
+    WHERE mr.message_external_id = mc.message_external_id
+      AND mr.reaction IN ('👍', '👎', '👌', '🚩')
+  ) answers ON TRUE
+  GROUP BY mc.chunk_external_id
+  ON CONFLICT (chunk_external_id) DO UPDATE SET
+    feedback_score = EXCLUDED.feedback_score,
+    feedback_weight = EXCLUDED.feedback_weight,
+    rated_answers = EXCLUDED.rated_answers,
+    upvoted_answers = EXCLUDED.upvoted_answers,
+    downvoted_answers = EXCLUDED.downvoted_answers,
+    updated_at = NOW();
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION message_reactions_feedback_trigger() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF (TG_OP = 'INSERT') THEN
+    IF NEW.reaction NOT IN ('👍', '👎', '👌', '🚩') THEN
+      RETURN NEW;
+    END IF;
+    PERFORM update_chunk_feedback(NEW.message_external_id);
+    RETURN NEW;
+  ELSIF (TG_OP = 'UPDATE') THEN
+    IF OLD.message_external_id <> NEW.message_external_id THEN
+      PERFORM update_chunk_feedback(OLD.message_external_id);
+    END IF;
+    PERFORM update_chunk_feedback(NEW.message_external_id);
+    RETURN NEW;
+  ELSIF (TG_OP = 'DELETE') THEN
+    IF OLD.reaction NOT IN ('👍', '👎', '👌', '🚩') THEN
+      RETURN OLD;
+    END IF;
+    PERFORM update_chunk_feedback(OLD.message_external_id);
+    RETURN OLD;
+  END IF;
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_message_reactions_feedback ON message_reactions;
+CREATE TRIGGER trg_message_reactions_feedback
+AFTER INSERT OR UPDATE OR DELETE ON message_reactions
+FOR EACH ROW EXECUTE FUNCTION message_reactions_feedback_trigger();
+
+CREATE OR REPLACE FUNCTION recompute_all_chunk_feedback() RETURNS VOID
+LANGUAGE plpgsql AS $$
+BEGIN
+  DELETE FROM chunk_feedback;
+
+  INSERT INTO chunk_feedback (
+    chunk_external_id, feedback_score, feedback_weight,
+    rated_answers, upvoted_answers, downvoted_answers, updated_at
+  )
+  SELECT mc.chunk_external_id,
+         SUM(answers.net),
+         SUM(ABS(answers.net)),
+         COUNT(*),
+         COUNT(*) FILTER (WHERE answers.net > 0),
+         COUNT(*) FILTER (WHERE answers.net < 0),
+         NOW()
+  FROM (
+    SELECT DISTINCT message_external_id, chunk_external_id
+    FROM message_citations
+    WHERE chunk_external_id IS NOT NULL
+  ) mc
+  JOIN (
+    SELECT message_external_id, SUM(score) AS net
+    FROM message_reactions
+    WHERE reaction IN ('👍', '👎', '👌', '🚩')
+    GROUP BY message_external_id
+  ) answers ON answers.message_external_id = mc.message_external_id
+  GROUP BY mc.chunk_external_id;
+END;
+$$;
+
+SELECT recompute_all_chunk_feedback();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_message_reactions_feedback ON message_reactions;
+DROP FUNCTION IF EXISTS message_reactions_feedback_trigger();
+DROP FUNCTION IF EXISTS update_chunk_feedback(uuid);
+DROP FUNCTION IF EXISTS recompute_all_chunk_feedback();
+DROP INDEX IF EXISTS idx_chunk_feedback_downvoted;
+DROP TABLE IF EXISTS chunk_feedback;
+-- +goose StatementEnd
\ No newline at end of file
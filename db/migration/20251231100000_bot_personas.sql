@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bot_personas (
+    persona_id              BIGSERIAL,
+    slug                    TEXT              PRIMARY KEY,
+    display_name            TEXT              NOT NULL,
+    avatar_url              TEXT,
+    prompt_name             TEXT              NOT NULL DEFAULT 'default',
+    departments             TEXT[]            NOT NULL DEFAULT '{}',
+    source_ids              UUID[]            NOT NULL DEFAULT '{}',
+    chat_model              TEXT,
+    temperature             DOUBLE PRECISION,
+    handoff_min_similarity  DOUBLE PRECISION,
+    handoff_phrases         TEXT[]            NOT NULL DEFAULT '{}',
+    label_keywords          TEXT[]            NOT NULL DEFAULT '{}',
+    entry_points            TEXT[]            NOT NULL DEFAULT '{}',
+    created_at              TIMESTAMPTZ       NOT NULL DEFAULT now(),
+    updated_at              TIMESTAMPTZ       NOT NULL DEFAULT now()
+);
+
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS entry_point TEXT;
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS persona_slug TEXT
+    REFERENCES bot_personas (slug) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS persona_slug;
+ALTER TABLE chats DROP COLUMN IF EXISTS entry_point;
+DROP TABLE IF EXISTS bot_personas;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS message_tool_calls (
+    tool_call_id           BIGSERIAL,
+    tool_call_external_id  UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_external_id    UUID         NOT NULL,
+    chat_external_id       UUID         NOT NULL,
+    call_id                TEXT         NOT NULL,
+    tool_name              TEXT         NOT NULL,
+    arguments              JSONB        NOT NULL DEFAULT '{}',
+    result                 TEXT         NOT NULL,
+    is_error               BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at             TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    CONSTRAINT fk_tool_calls_message FOREIGN KEY (message_external_id)
+        REFERENCES messages (message_external_id) ON DELETE CASCADE,
+    CONSTRAINT fk_tool_calls_chat FOREIGN KEY (chat_external_id)
+        REFERENCES chats (chat_external_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_chat ON message_tool_calls(chat_external_id, created_at);
+
+CREATE TABLE IF NOT EXISTS follow_up_tickets (
+    ticket_id            BIGSERIAL,
+    ticket_external_id   UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    chat_external_id     UUID         NOT NULL,
+    user_external_id     UUID         NOT NULL,
+    department           TEXT,
+    subject              TEXT         NOT NULL,
+    details              TEXT,
+    status               TEXT         NOT NULL DEFAULT 'open',
+    resolved_by          UUID,
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    resolved_at          TIMESTAMPTZ,
+    CONSTRAINT chk_tickets_status CHECK (status IN ('open', 'resolved')),
+    CONSTRAINT fk_tickets_chat FOREIGN KEY (chat_external_id)
+        REFERENCES chats (chat_external_id) ON DELETE CASCADE,
+    CONSTRAINT fk_tickets_user FOREIGN KEY (user_external_id)
+        REFERENCES users (user_external_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tickets_status ON follow_up_tickets(status, created_at);
+-- One open ticket per chat, however often the bot is asked to open one.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_open_chat
+    ON follow_up_tickets(chat_external_id) WHERE status = 'open';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tickets_open_chat;
+DROP INDEX IF EXISTS idx_tickets_status;
+DROP TABLE IF EXISTS follow_up_tickets;
+DROP INDEX IF EXISTS idx_tool_calls_chat;
+DROP TABLE IF EXISTS message_tool_calls;
+-- +goose StatementEnd
@@ -1,8 +1,9 @@
 -- name: CreateKnowledge :one
 INSERT INTO ai_knowledge (
-  source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_by
+  source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_by,
+  chat_external_id, question_message_id, answer_message_id
 ) VALUES (
-  $1, $2, COALESCE($3, '{}'::jsonb), $4, $5, $6
+  $1, $2, COALESCE($3, '{}'::jsonb), $4, $5, $6, $7, $8, $9
 )
 RETURNING *;
 
@@ -11,6 +12,17 @@ SELECT * FROM ai_knowledge
 WHERE knowledge_external_id = $1
 LIMIT 1;
 
+-- name: GetKnowledgeByAnswerMessage :one
+SELECT * FROM ai_knowledge
+WHERE answer_message_id = $1
+LIMIT 1;
+
+-- name: ListKnowledge :many
+SELECT * FROM ai_knowledge
+WHERE (sqlc.narg(chat_external_id)::uuid IS NULL OR chat_external_id = sqlc.narg(chat_external_id)::uuid)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit)::int;
+
 -- name: SearchKnowledgeFulltext :many
 SELECT 
     id, knowledge_external_id, source_text, ts_rank_cd(to_tsvector('simple', source_text), to_tsquery('simple', $1)) AS rank
@@ -0,0 +1,34 @@
+-- name: UpsertBotPersona :one
+INSERT INTO bot_personas (
+  slug, display_name, avatar_url, prompt_name, departments, source_ids,
+  chat_model, temperature, handoff_min_similarity, handoff_phrases,
+  label_keywords, entry_points
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (slug) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    avatar_url = EXCLUDED.avatar_url,
+    prompt_name = EXCLUDED.prompt_name,
+    departments = EXCLUDED.departments,
+    source_ids = EXCLUDED.source_ids,
+    chat_model = EXCLUDED.chat_model,
+    temperature = EXCLUDED.temperature,
+    handoff_min_similarity = EXCLUDED.handoff_min_similarity,
+    handoff_phrases = EXCLUDED.handoff_phrases,
+    label_keywords = EXCLUDED.label_keywords,
+    entry_points = EXCLUDED.entry_points,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetBotPersona :one
+SELECT * FROM bot_personas
+WHERE slug = $1;
+
+-- name: ListBotPersonas :many
+SELECT * FROM bot_personas
+ORDER BY slug;
+
+-- name: DeleteBotPersona :exec
+DELETE FROM bot_personas
+WHERE slug = $1;
@@ -5,12 +5,15 @@ INSERT INTO chats (
     label,
     admin_external_id,
     score,
+    department,
+    entry_point,
+    persona_slug,
     created_at,
     updated_at
 ) VALUES (
-    $1, $2::chat_status_type, $3, $4, $5, NOW(), NOW()
+    $1, $2::chat_status_type, $3, $4, $5, $6, $7, $8, NOW(), NOW()
 )
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at;
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
 
 -- name: CreateChatDefaults :one
 INSERT INTO chats (
@@ -21,23 +24,23 @@ INSERT INTO chats (
 ) VALUES (
     $1, $2, NOW(), NOW()
 )
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at;
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
 
 -- name: AssignedAdminToChat :one
 UPDATE chats
 SET admin_external_id = $2,
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at;
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
 
 -- name: GetChat :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE chat_external_id = $1
 LIMIT 1;
 
 -- name: GetChatsByUser :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
 ORDER BY created_at DESC
@@ -45,7 +48,7 @@ LIMIT $2
 OFFSET $3;
 
 -- name: ListChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 ORDER BY updated_at DESC
 LIMIT $1
@@ -56,7 +59,7 @@ UPDATE chats
 SET status = $2::chat_status_type,
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at;
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
 
 -- name: UpdateChat :one
 UPDATE chats
@@ -67,21 +70,21 @@ SET
     admin_external_id = COALESCE(NULLIF($5, '00000000-0000-0000-0000-000000000000'::uuid), admin_external_id),
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at;
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
 
 -- name: UpdateChatScore :one
 UPDATE chats
 SET score = $2,
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at;
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
 
 -- name: DeleteChat :exec
 DELETE FROM chats
 WHERE chat_external_id = $1;
 
 -- name: GetOpenChatByUser :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
   AND status = 'open'::chat_status_type
@@ -90,7 +93,7 @@ LIMIT 1
 FOR UPDATE;
 
 -- name: GetPendingChatByUser :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
   AND status = 'pending'::chat_status_type
@@ -99,7 +102,7 @@ LIMIT 1
 FOR UPDATE;
 
 -- name: GetClosedChatByUser :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
   AND status = 'closed'::chat_status_type
@@ -108,7 +111,7 @@ LIMIT 1
 FOR UPDATE;
 
 -- name: ListPendingChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = 'pending'::chat_status_type
 ORDER BY updated_at DESC
@@ -116,7 +119,7 @@ LIMIT $1
 OFFSET $2;
 
 -- name: ListOpenChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = 'open'::chat_status_type
 ORDER BY updated_at DESC
@@ -124,7 +127,7 @@ LIMIT $1
 OFFSET $2;
 
 -- name: ListClosedChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = 'closed'::chat_status_type
 ORDER BY updated_at DESC
@@ -132,7 +135,7 @@ LIMIT $1
 OFFSET $2;
 
 -- name: GetChatsByAdmin :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE admin_external_id = $1
 ORDER BY updated_at DESC
@@ -145,7 +148,7 @@ FROM chats
 WHERE user_external_id = $1;
 
 -- name: GetChatsByStatusAndScoreRange :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = $1
   AND ($2 IS NULL OR score >= $2)
@@ -155,8 +158,32 @@ LIMIT $4
 OFFSET $5;
 
 -- name: GetTopChatsByScore :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 ORDER BY score DESC NULLS LAST, updated_at DESC
 LIMIT $1
 OFFSET $2;
+
+-- name: SetChatDepartment :one
+UPDATE chats
+SET department = $2,
+    updated_at = NOW()
+WHERE chat_external_id = $1
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
+
+-- name: SetChatPersona :one
+UPDATE chats
+SET persona_slug = $2,
+    updated_at = NOW()
+WHERE chat_external_id = $1
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug;
+
+-- name: ListDepartmentQueue :many
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
+FROM chats
+WHERE department IS NOT DISTINCT FROM $1
+  AND status IN ('waiting'::chat_status_type, 'open'::chat_status_type, 'pending'::chat_status_type)
+  AND admin_external_id IS NULL
+ORDER BY status = 'waiting'::chat_status_type DESC, updated_at ASC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,22 @@
+-- name: CreateChatHandoff :one
+INSERT INTO chat_handoffs (
+    chat_external_id,
+    message_external_id,
+    reason,
+    detail,
+    similarity
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetLatestHandoffByChat :one
+SELECT * FROM chat_handoffs
+WHERE chat_external_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListHandoffsByChat :many
+SELECT * FROM chat_handoffs
+WHERE chat_external_id = $1
+ORDER BY created_at DESC;
@@ -1,8 +1,8 @@
 -- name: CreateChunk :one
 INSERT INTO chunks (
-  source_id, source_path, source_filename, source_mime, source_page, department, language, text, embedding_vector, embedding_json, chunk_hash, created_by, status
+  source_id, source_path, source_filename, source_mime, source_page, department, language, text, embedding_vector, embedding_json, chunk_hash, created_by, status, heading_path, chunk_external_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13, 'ready')
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13, 'ready'), $14, COALESCE(sqlc.narg(chunk_external_id)::uuid, gen_random_uuid())
 )
 RETURNING *;
 
@@ -15,6 +15,14 @@ SELECT * FROM chunks
 WHERE source_id = $1
 ORDER BY created_at DESC;
 
+-- name: ListChunkHashesBySource :many
+SELECT chunk_external_id, chunk_hash, status FROM chunks
+WHERE source_id = $1;
+
+-- name: ListChunkHashesBySourcePath :many
+SELECT chunk_external_id, chunk_hash, status FROM chunks
+WHERE source_id IS NULL AND source_path = $1;
+
 -- name: SearchChunksFulltext :many
 SELECT chunk_external_id, text, ts_rank_cd(text_tsv, query) AS rank
 FROM chunks, to_tsquery('simple', $1) AS query
@@ -36,3 +44,7 @@ WHERE chunk_external_id = $1;
 -- name: DeleteChunk :exec
 DELETE FROM chunks
 WHERE chunk_external_id = $1;
+
+-- name: DeleteChunksBySource :exec
+DELETE FROM chunks
+WHERE source_id = $1;
@@ -0,0 +1,33 @@
+-- name: ListDownvotedChunks :many
+SELECT cf.chunk_external_id,
+       c.source_id,
+       c.source_filename,
+       c.source_page,
+       c.heading_path,
+       c.status,
+       left(c.text, 200)::text AS excerpt,
+       cf.feedback_score,
+       cf.rated_answers,
+       cf.upvoted_answers,
+       cf.downvoted_answers,
+       cf.updated_at
+FROM chunk_feedback cf
+JOIN chunks c ON c.chunk_external_id = cf.chunk_external_id
+WHERE cf.downvoted_answers > 0
+  AND (sqlc.narg(source_id)::uuid IS NULL OR c.source_id = sqlc.narg(source_id)::uuid)
+ORDER BY cf.downvoted_answers DESC, cf.feedback_score ASC
+LIMIT sqlc.arg(row_limit)::int;
+
+-- name: ListDownvotedSources :many
+SELECT c.source_id,
+       COALESCE(c.source_filename, '')::text AS source_filename,
+       COUNT(*) AS downvoted_chunks,
+       SUM(cf.feedback_score)::bigint AS feedback_score,
+       SUM(cf.rated_answers)::bigint AS rated_answers,
+       SUM(cf.downvoted_answers)::bigint AS downvoted_answers
+FROM chunk_feedback cf
+JOIN chunks c ON c.chunk_external_id = cf.chunk_external_id
+WHERE cf.downvoted_answers > 0
+GROUP BY c.source_id, COALESCE(c.source_filename, '')
+ORDER BY downvoted_answers DESC, feedback_score ASC
+LIMIT sqlc.arg(row_limit)::int;
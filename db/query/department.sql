@@ -0,0 +1,23 @@
+-- name: UpsertDepartment :one
+INSERT INTO departments (
+  slug, name, keywords
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (slug) DO UPDATE
+SET name = EXCLUDED.name,
+    keywords = EXCLUDED.keywords,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetDepartment :one
+SELECT * FROM departments
+WHERE slug = $1;
+
+-- name: ListDepartments :many
+SELECT * FROM departments
+ORDER BY slug;
+
+-- name: DeleteDepartment :exec
+DELETE FROM departments
+WHERE slug = $1;
@@ -0,0 +1,32 @@
+-- name: GetCachedEmbeddings :many
+SELECT content_hash, embedding FROM embedding_cache
+WHERE model = sqlc.arg(model)
+  AND content_hash = ANY(sqlc.arg(hashes)::text[]);
+
+-- name: TouchCachedEmbeddings :exec
+UPDATE embedding_cache
+SET hits = hits + 1,
+    last_used_at = now()
+WHERE model = sqlc.arg(model)
+  AND content_hash = ANY(sqlc.arg(hashes)::text[]);
+
+-- name: InsertCachedEmbedding :exec
+INSERT INTO embedding_cache (
+    content_hash, model, embedding
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (content_hash, model) DO NOTHING;
+
+-- name: GetEmbeddingCacheStats :many
+SELECT model,
+       COUNT(*) AS entries,
+       COALESCE(SUM(hits), 0)::bigint AS hits,
+       MAX(last_used_at)::timestamptz AS last_used_at
+FROM embedding_cache
+GROUP BY model
+ORDER BY model;
+
+-- name: DeleteUnusedEmbeddings :exec
+DELETE FROM embedding_cache
+WHERE last_used_at < $1;
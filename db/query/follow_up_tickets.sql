@@ -0,0 +1,30 @@
+-- name: CreateFollowUpTicket :one
+-- An open ticket for the chat is returned as it is rather than duplicated.
+INSERT INTO follow_up_tickets (
+    chat_external_id,
+    user_external_id,
+    department,
+    subject,
+    details
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (chat_external_id) WHERE status = 'open'
+DO UPDATE SET chat_external_id = EXCLUDED.chat_external_id
+RETURNING *;
+
+-- name: ListFollowUpTickets :many
+SELECT * FROM follow_up_tickets
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(department)::text IS NULL OR department = sqlc.narg(department)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit)::int
+OFFSET sqlc.arg(row_offset)::int;
+
+-- name: ResolveFollowUpTicket :one
+UPDATE follow_up_tickets
+SET status = 'resolved',
+    resolved_by = $2,
+    resolved_at = now()
+WHERE ticket_external_id = $1 AND status = 'open'
+RETURNING *;
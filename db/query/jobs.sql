@@ -0,0 +1,95 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (
+  kind, payload, max_attempts, run_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE job_external_id = $1;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = now(),
+    locked_by = sqlc.arg(worker_id),
+    updated_at = now()
+WHERE job_external_id = (
+  SELECT j.job_external_id FROM jobs j
+  WHERE j.status = 'queued'
+    AND j.run_at <= now()
+    AND j.kind = ANY(sqlc.arg(kinds)::text[])
+  ORDER BY j.run_at
+  FOR UPDATE SKIP LOCKED
+  LIMIT 1
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'succeeded',
+    locked_at = NULL,
+    locked_by = NULL,
+    updated_at = now(),
+    finished_at = now()
+WHERE job_external_id = $1;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET status = 'queued',
+    run_at = $2,
+    last_error = $3,
+    locked_at = NULL,
+    locked_by = NULL,
+    updated_at = now()
+WHERE job_external_id = $1;
+
+-- name: DeadLetterJob :exec
+UPDATE jobs
+SET status = 'dead',
+    last_error = $2,
+    locked_at = NULL,
+    locked_by = NULL,
+    updated_at = now(),
+    finished_at = now()
+WHERE job_external_id = $1;
+
+-- name: RequeueStaleJobs :exec
+UPDATE jobs
+SET status = 'queued',
+    locked_at = NULL,
+    locked_by = NULL,
+    updated_at = now()
+WHERE status = 'running'
+  AND locked_at < $1;
+
+-- name: RetryDeadJob :one
+UPDATE jobs
+SET status = 'queued',
+    attempts = 0,
+    run_at = now(),
+    updated_at = now(),
+    finished_at = NULL
+WHERE job_external_id = $1
+  AND status = 'dead'
+RETURNING *;
+
+-- name: DeleteFinishedJobs :exec
+DELETE FROM jobs
+WHERE status = 'succeeded'
+  AND finished_at < $1;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit)::int;
+
+-- name: CountJobsByStatus :many
+SELECT status, COUNT(*) AS total
+FROM jobs
+GROUP BY status
+ORDER BY status;
@@ -33,6 +33,18 @@ WHERE chat_external_id = $1
 ORDER BY created_at DESC
 LIMIT $2;
 
+-- name: ListMessagesBeforeMessage :many
+-- The messages sent before the given one, newest first. message_id breaks
+-- ties between messages stored within the same clock tick.
+SELECT message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at
+FROM messages
+WHERE chat_external_id = $1
+  AND (created_at, message_id) < (
+    SELECT created_at, message_id FROM messages WHERE message_external_id = $2
+  )
+ORDER BY created_at DESC, message_id DESC
+LIMIT $3;
+
 -- name: EditMessage :one
 UPDATE messages
 SET content = $2,
@@ -51,6 +63,17 @@ WHERE chat_external_id = $1
 ORDER BY created_at DESC
 LIMIT 1;
 
+-- name: GetQuestionBeforeMessage :one
+-- The message an answer replies to: the chat user's latest one before it.
+SELECT message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at
+FROM messages
+WHERE chat_external_id = $1
+  AND created_at < $2
+  AND sender_external_id = (SELECT user_external_id FROM chats WHERE chats.chat_external_id = $1)
+  AND NOT is_system_message
+ORDER BY created_at DESC
+LIMIT 1;
+
 -- name: CountMessagesByChat :one
 SELECT COUNT(*) AS count
 FROM messages
@@ -82,3 +105,8 @@ SET is_system_message = TRUE,
     updated_at = NOW()
 WHERE message_external_id = $1
 RETURNING message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at;
+
+-- name: SetMessagePromptTemplate :exec
+UPDATE messages
+SET prompt_template_id = $2
+WHERE message_external_id = $1;
@@ -0,0 +1,17 @@
+-- name: CreateMessageCitation :one
+INSERT INTO message_citations (
+    message_external_id,
+    chunk_external_id,
+    source_id,
+    source_filename,
+    source_page,
+    position
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: ListCitationsByMessage :many
+SELECT * FROM message_citations
+WHERE message_external_id = $1
+ORDER BY position ASC;
@@ -0,0 +1,18 @@
+-- name: CreateMessageToolCall :one
+INSERT INTO message_tool_calls (
+    message_external_id,
+    chat_external_id,
+    call_id,
+    tool_name,
+    arguments,
+    result,
+    is_error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: ListToolCallsByChat :many
+SELECT * FROM message_tool_calls
+WHERE chat_external_id = $1
+ORDER BY created_at ASC;
@@ -0,0 +1,46 @@
+-- name: CreatePromptTemplate :one
+INSERT INTO prompt_templates (
+    name,
+    version,
+    system_prompt,
+    context_prompt,
+    created_by
+) VALUES (
+    $1,
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM prompt_templates WHERE name = $1),
+    $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetPromptTemplate :one
+SELECT * FROM prompt_templates
+WHERE template_external_id = $1;
+
+-- name: GetActivePromptTemplate :one
+SELECT * FROM prompt_templates
+WHERE name = $1 AND is_active;
+
+-- name: ListPromptTemplates :many
+SELECT * FROM prompt_templates
+WHERE (sqlc.arg(name)::text = '' OR name = sqlc.arg(name)::text)
+ORDER BY name, version DESC;
+
+-- name: DeactivatePromptTemplates :exec
+UPDATE prompt_templates
+SET is_active = FALSE
+WHERE name = $1 AND is_active;
+
+-- name: ActivatePromptTemplate :one
+UPDATE prompt_templates
+SET is_active = TRUE
+WHERE template_external_id = $1
+RETURNING *;
+
+-- name: DeletePromptTemplate :execrows
+-- Versions that are live or that produced a message are kept for the record.
+DELETE FROM prompt_templates
+WHERE template_external_id = $1
+  AND NOT is_active
+  AND NOT EXISTS (
+    SELECT 1 FROM messages WHERE prompt_template_id = $1
+  );
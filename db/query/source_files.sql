@@ -1,8 +1,8 @@
 -- name: CreateSourceFile :one
 INSERT INTO source_files (
-  storage_key, filename, mime_type, size_bytes, uploaded_by
+  storage_key, filename, mime_type, size_bytes, uploaded_by, department
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
@@ -10,11 +10,34 @@ RETURNING *;
 SELECT * FROM source_files
 WHERE source_external_id = $1;
 
+-- name: ClaimSourceForProcessing :one
+UPDATE source_files
+SET status = 'processing',
+    error_message = NULL
+WHERE source_external_id = $1
+  AND status <> 'processed'
+RETURNING *;
+
 -- name: MarkSourceProcessed :exec
 UPDATE source_files
 SET processed_at = now(),
-    status = 'processed'
-WHERE source_id = $1;
+    status = 'processed',
+    error_message = NULL
+WHERE source_external_id = $1;
+
+-- name: MarkSourceFailed :exec
+UPDATE source_files
+SET status = 'failed',
+    error_message = $2
+WHERE source_external_id = $1;
+
+-- name: ResetSourceStatus :one
+UPDATE source_files
+SET status = 'uploaded',
+    error_message = NULL,
+    processed_at = NULL
+WHERE source_external_id = $1
+RETURNING *;
 
 -- name: ListUploadedSources :many
 SELECT *
@@ -26,4 +49,8 @@ LIMIT $1;
 SELECT source_id, source_external_id, filename, mime_type, size_bytes, uploaded_at, status
 FROM source_files
 WHERE uploaded_by = $1
-ORDER BY uploaded_at DESC;
\ No newline at end of file
+ORDER BY uploaded_at DESC;
+
+-- name: DeleteSourceFile :exec
+DELETE FROM source_files
+WHERE source_external_id = $1;
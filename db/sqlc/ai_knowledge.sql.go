@@ -14,20 +14,24 @@ import (
 
 const createKnowledge = `-- name: CreateKnowledge :one
 INSERT INTO ai_knowledge (
-  source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_by
+  source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_by,
+  chat_external_id, question_message_id, answer_message_id
 ) VALUES (
-  $1, $2, COALESCE($3, '{}'::jsonb), $4, $5, $6
+  $1, $2, COALESCE($3, '{}'::jsonb), $4, $5, $6, $7, $8, $9
 )
-RETURNING id, knowledge_external_id, source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_at, created_by
+RETURNING id, knowledge_external_id, source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_at, created_by, chat_external_id, question_message_id, answer_message_id
 `
 
 type CreateKnowledgeParams struct {
-	SourceChunkID   pgtype.UUID `json:"source_chunk_id"`
-	SourceText      string      `json:"source_text"`
-	Column3         interface{} `json:"column_3"`
-	EmbeddingVector []float64   `json:"embedding_vector"`
-	EmbeddingJson   []byte      `json:"embedding_json"`
-	CreatedBy       pgtype.UUID `json:"created_by"`
+	SourceChunkID     pgtype.UUID `json:"source_chunk_id"`
+	SourceText        string      `json:"source_text"`
+	Column3           interface{} `json:"column_3"`
+	EmbeddingVector   []float64   `json:"embedding_vector"`
+	EmbeddingJson     []byte      `json:"embedding_json"`
+	CreatedBy         pgtype.UUID `json:"created_by"`
+	ChatExternalID    pgtype.UUID `json:"chat_external_id"`
+	QuestionMessageID pgtype.UUID `json:"question_message_id"`
+	AnswerMessageID   pgtype.UUID `json:"answer_message_id"`
 }
 
 func (q *Queries) CreateKnowledge(ctx context.Context, arg CreateKnowledgeParams) (AiKnowledge, error) {
@@ -38,6 +42,9 @@ func (q *Queries) CreateKnowledge(ctx context.Context, arg CreateKnowledgeParams
 		arg.EmbeddingVector,
 		arg.EmbeddingJson,
 		arg.CreatedBy,
+		arg.ChatExternalID,
+		arg.QuestionMessageID,
+		arg.AnswerMessageID,
 	)
 	var i AiKnowledge
 	err := row.Scan(
@@ -50,6 +57,9 @@ func (q *Queries) CreateKnowledge(ctx context.Context, arg CreateKnowledgeParams
 		&i.EmbeddingJson,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.ChatExternalID,
+		&i.QuestionMessageID,
+		&i.AnswerMessageID,
 	)
 	return i, err
 }
@@ -64,8 +74,34 @@ func (q *Queries) DeleteKnowledge(ctx context.Context, knowledgeExternalID uuid.
 	return err
 }
 
+const getKnowledgeByAnswerMessage = `-- name: GetKnowledgeByAnswerMessage :one
+SELECT id, knowledge_external_id, source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_at, created_by, chat_external_id, question_message_id, answer_message_id FROM ai_knowledge
+WHERE answer_message_id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetKnowledgeByAnswerMessage(ctx context.Context, answerMessageID pgtype.UUID) (AiKnowledge, error) {
+	row := q.db.QueryRow(ctx, getKnowledgeByAnswerMessage, answerMessageID)
+	var i AiKnowledge
+	err := row.Scan(
+		&i.ID,
+		&i.KnowledgeExternalID,
+		&i.SourceChunkID,
+		&i.SourceText,
+		&i.SourceMeta,
+		&i.EmbeddingVector,
+		&i.EmbeddingJson,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.ChatExternalID,
+		&i.QuestionMessageID,
+		&i.AnswerMessageID,
+	)
+	return i, err
+}
+
 const getKnowledgeByID = `-- name: GetKnowledgeByID :one
-SELECT id, knowledge_external_id, source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_at, created_by FROM ai_knowledge
+SELECT id, knowledge_external_id, source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_at, created_by, chat_external_id, question_message_id, answer_message_id FROM ai_knowledge
 WHERE knowledge_external_id = $1
 LIMIT 1
 `
@@ -83,10 +119,58 @@ func (q *Queries) GetKnowledgeByID(ctx context.Context, knowledgeExternalID uuid
 		&i.EmbeddingJson,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.ChatExternalID,
+		&i.QuestionMessageID,
+		&i.AnswerMessageID,
 	)
 	return i, err
 }
 
+const listKnowledge = `-- name: ListKnowledge :many
+SELECT id, knowledge_external_id, source_chunk_id, source_text, source_meta, embedding_vector, embedding_json, created_at, created_by, chat_external_id, question_message_id, answer_message_id FROM ai_knowledge
+WHERE ($1::uuid IS NULL OR chat_external_id = $1::uuid)
+ORDER BY created_at DESC
+LIMIT $2::int
+`
+
+type ListKnowledgeParams struct {
+	ChatExternalID pgtype.UUID `json:"chat_external_id"`
+	RowLimit       int32       `json:"row_limit"`
+}
+
+func (q *Queries) ListKnowledge(ctx context.Context, arg ListKnowledgeParams) ([]AiKnowledge, error) {
+	rows, err := q.db.Query(ctx, listKnowledge, arg.ChatExternalID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AiKnowledge
+	for rows.Next() {
+		var i AiKnowledge
+		if err := rows.Scan(
+			&i.ID,
+			&i.KnowledgeExternalID,
+			&i.SourceChunkID,
+			&i.SourceText,
+			&i.SourceMeta,
+			&i.EmbeddingVector,
+			&i.EmbeddingJson,
+			&i.CreatedAt,
+			&i.CreatedBy,
+			&i.ChatExternalID,
+			&i.QuestionMessageID,
+			&i.AnswerMessageID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchKnowledgeFulltext = `-- name: SearchKnowledgeFulltext :many
 SELECT 
     id, knowledge_external_id, source_text, ts_rank_cd(to_tsvector('simple', source_text), to_tsquery('simple', $1)) AS rank
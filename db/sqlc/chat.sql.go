@@ -17,7 +17,7 @@ UPDATE chats
 SET admin_external_id = $2,
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 `
 
 type AssignedAdminToChatParams struct {
@@ -38,6 +38,9 @@ func (q *Queries) AssignedAdminToChat(ctx context.Context, arg AssignedAdminToCh
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
@@ -62,12 +65,15 @@ INSERT INTO chats (
     label,
     admin_external_id,
     score,
+    department,
+    entry_point,
+    persona_slug,
     created_at,
     updated_at
 ) VALUES (
-    $1, $2::chat_status_type, $3, $4, $5, NOW(), NOW()
+    $1, $2::chat_status_type, $3, $4, $5, $6, $7, $8, NOW(), NOW()
 )
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 `
 
 type CreateChatParams struct {
@@ -76,6 +82,9 @@ type CreateChatParams struct {
 	Label           string      `json:"label"`
 	AdminExternalID pgtype.UUID `json:"admin_external_id"`
 	Score           pgtype.Int8 `json:"score"`
+	Department      pgtype.Text `json:"department"`
+	EntryPoint      pgtype.Text `json:"entry_point"`
+	PersonaSlug     pgtype.Text `json:"persona_slug"`
 }
 
 func (q *Queries) CreateChat(ctx context.Context, arg CreateChatParams) (Chat, error) {
@@ -85,6 +94,9 @@ func (q *Queries) CreateChat(ctx context.Context, arg CreateChatParams) (Chat, e
 		arg.Label,
 		arg.AdminExternalID,
 		arg.Score,
+		arg.Department,
+		arg.EntryPoint,
+		arg.PersonaSlug,
 	)
 	var i Chat
 	err := row.Scan(
@@ -97,6 +109,9 @@ func (q *Queries) CreateChat(ctx context.Context, arg CreateChatParams) (Chat, e
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
@@ -110,7 +125,7 @@ INSERT INTO chats (
 ) VALUES (
     $1, $2, NOW(), NOW()
 )
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 `
 
 type CreateChatDefaultsParams struct {
@@ -131,6 +146,9 @@ func (q *Queries) CreateChatDefaults(ctx context.Context, arg CreateChatDefaults
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
@@ -146,7 +164,7 @@ func (q *Queries) DeleteChat(ctx context.Context, chatExternalID uuid.UUID) erro
 }
 
 const getChat = `-- name: GetChat :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE chat_external_id = $1
 LIMIT 1
@@ -165,12 +183,15 @@ func (q *Queries) GetChat(ctx context.Context, chatExternalID uuid.UUID) (Chat,
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
 
 const getChatsByAdmin = `-- name: GetChatsByAdmin :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE admin_external_id = $1
 ORDER BY updated_at DESC
@@ -203,6 +224,9 @@ func (q *Queries) GetChatsByAdmin(ctx context.Context, arg GetChatsByAdminParams
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -215,7 +239,7 @@ func (q *Queries) GetChatsByAdmin(ctx context.Context, arg GetChatsByAdminParams
 }
 
 const getChatsByStatusAndScoreRange = `-- name: GetChatsByStatusAndScoreRange :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = $1
   AND ($2 IS NULL OR score >= $2)
@@ -258,6 +282,9 @@ func (q *Queries) GetChatsByStatusAndScoreRange(ctx context.Context, arg GetChat
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -270,7 +297,7 @@ func (q *Queries) GetChatsByStatusAndScoreRange(ctx context.Context, arg GetChat
 }
 
 const getChatsByUser = `-- name: GetChatsByUser :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
 ORDER BY created_at DESC
@@ -303,6 +330,9 @@ func (q *Queries) GetChatsByUser(ctx context.Context, arg GetChatsByUserParams)
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -315,7 +345,7 @@ func (q *Queries) GetChatsByUser(ctx context.Context, arg GetChatsByUserParams)
 }
 
 const getClosedChatByUser = `-- name: GetClosedChatByUser :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
   AND status = 'closed'::chat_status_type
@@ -337,12 +367,15 @@ func (q *Queries) GetClosedChatByUser(ctx context.Context, userExternalID uuid.U
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
 
 const getOpenChatByUser = `-- name: GetOpenChatByUser :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
   AND status = 'open'::chat_status_type
@@ -364,12 +397,15 @@ func (q *Queries) GetOpenChatByUser(ctx context.Context, userExternalID uuid.UUI
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
 
 const getPendingChatByUser = `-- name: GetPendingChatByUser :one
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE user_external_id = $1
   AND status = 'pending'::chat_status_type
@@ -391,12 +427,15 @@ func (q *Queries) GetPendingChatByUser(ctx context.Context, userExternalID uuid.
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
 
 const getTopChatsByScore = `-- name: GetTopChatsByScore :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 ORDER BY score DESC NULLS LAST, updated_at DESC
 LIMIT $1
@@ -427,6 +466,9 @@ func (q *Queries) GetTopChatsByScore(ctx context.Context, arg GetTopChatsByScore
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -439,7 +481,7 @@ func (q *Queries) GetTopChatsByScore(ctx context.Context, arg GetTopChatsByScore
 }
 
 const listChats = `-- name: ListChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 ORDER BY updated_at DESC
 LIMIT $1
@@ -470,6 +512,9 @@ func (q *Queries) ListChats(ctx context.Context, arg ListChatsParams) ([]Chat, e
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -482,7 +527,7 @@ func (q *Queries) ListChats(ctx context.Context, arg ListChatsParams) ([]Chat, e
 }
 
 const listClosedChats = `-- name: ListClosedChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = 'closed'::chat_status_type
 ORDER BY updated_at DESC
@@ -514,6 +559,59 @@ func (q *Queries) ListClosedChats(ctx context.Context, arg ListClosedChatsParams
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDepartmentQueue = `-- name: ListDepartmentQueue :many
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
+FROM chats
+WHERE department IS NOT DISTINCT FROM $1
+  AND status IN ('waiting'::chat_status_type, 'open'::chat_status_type, 'pending'::chat_status_type)
+  AND admin_external_id IS NULL
+ORDER BY status = 'waiting'::chat_status_type DESC, updated_at ASC
+LIMIT $2
+OFFSET $3
+`
+
+type ListDepartmentQueueParams struct {
+	Department pgtype.Text `json:"department"`
+	Limit      int32       `json:"limit"`
+	Offset     int32       `json:"offset"`
+}
+
+func (q *Queries) ListDepartmentQueue(ctx context.Context, arg ListDepartmentQueueParams) ([]Chat, error) {
+	rows, err := q.db.Query(ctx, listDepartmentQueue, arg.Department, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chat
+	for rows.Next() {
+		var i Chat
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.ChatExternalID,
+			&i.UserExternalID,
+			&i.Label,
+			&i.Status,
+			&i.AdminExternalID,
+			&i.Score,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -526,7 +624,7 @@ func (q *Queries) ListClosedChats(ctx context.Context, arg ListClosedChatsParams
 }
 
 const listOpenChats = `-- name: ListOpenChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = 'open'::chat_status_type
 ORDER BY updated_at DESC
@@ -558,6 +656,9 @@ func (q *Queries) ListOpenChats(ctx context.Context, arg ListOpenChatsParams) ([
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -570,7 +671,7 @@ func (q *Queries) ListOpenChats(ctx context.Context, arg ListOpenChatsParams) ([
 }
 
 const listPendingChats = `-- name: ListPendingChats :many
-SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+SELECT chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 FROM chats
 WHERE status = 'pending'::chat_status_type
 ORDER BY updated_at DESC
@@ -602,6 +703,9 @@ func (q *Queries) ListPendingChats(ctx context.Context, arg ListPendingChatsPara
 			&i.Score,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Department,
+			&i.EntryPoint,
+			&i.PersonaSlug,
 		); err != nil {
 			return nil, err
 		}
@@ -613,6 +717,72 @@ func (q *Queries) ListPendingChats(ctx context.Context, arg ListPendingChatsPara
 	return items, nil
 }
 
+const setChatDepartment = `-- name: SetChatDepartment :one
+UPDATE chats
+SET department = $2,
+    updated_at = NOW()
+WHERE chat_external_id = $1
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
+`
+
+type SetChatDepartmentParams struct {
+	ChatExternalID uuid.UUID   `json:"chat_external_id"`
+	Department     pgtype.Text `json:"department"`
+}
+
+func (q *Queries) SetChatDepartment(ctx context.Context, arg SetChatDepartmentParams) (Chat, error) {
+	row := q.db.QueryRow(ctx, setChatDepartment, arg.ChatExternalID, arg.Department)
+	var i Chat
+	err := row.Scan(
+		&i.ChatID,
+		&i.ChatExternalID,
+		&i.UserExternalID,
+		&i.Label,
+		&i.Status,
+		&i.AdminExternalID,
+		&i.Score,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
+	)
+	return i, err
+}
+
+const setChatPersona = `-- name: SetChatPersona :one
+UPDATE chats
+SET persona_slug = $2,
+    updated_at = NOW()
+WHERE chat_external_id = $1
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
+`
+
+type SetChatPersonaParams struct {
+	ChatExternalID uuid.UUID   `json:"chat_external_id"`
+	PersonaSlug    pgtype.Text `json:"persona_slug"`
+}
+
+func (q *Queries) SetChatPersona(ctx context.Context, arg SetChatPersonaParams) (Chat, error) {
+	row := q.db.QueryRow(ctx, setChatPersona, arg.ChatExternalID, arg.PersonaSlug)
+	var i Chat
+	err := row.Scan(
+		&i.ChatID,
+		&i.ChatExternalID,
+		&i.UserExternalID,
+		&i.Label,
+		&i.Status,
+		&i.AdminExternalID,
+		&i.Score,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
+	)
+	return i, err
+}
+
 const updateChat = `-- name: UpdateChat :one
 UPDATE chats
 SET
@@ -622,7 +792,7 @@ SET
     admin_external_id = COALESCE(NULLIF($5, '00000000-0000-0000-0000-000000000000'::uuid), admin_external_id),
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 `
 
 type UpdateChatParams struct {
@@ -652,6 +822,9 @@ func (q *Queries) UpdateChat(ctx context.Context, arg UpdateChatParams) (Chat, e
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
@@ -661,7 +834,7 @@ UPDATE chats
 SET score = $2,
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 `
 
 type UpdateChatScoreParams struct {
@@ -682,6 +855,9 @@ func (q *Queries) UpdateChatScore(ctx context.Context, arg UpdateChatScoreParams
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
@@ -691,7 +867,7 @@ UPDATE chats
 SET status = $2::chat_status_type,
     updated_at = NOW()
 WHERE chat_external_id = $1
-RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at
+RETURNING chat_id, chat_external_id, user_external_id, label, status, admin_external_id, score, created_at, updated_at, department, entry_point, persona_slug
 `
 
 type UpdateChatStatusParams struct {
@@ -712,6 +888,9 @@ func (q *Queries) UpdateChatStatus(ctx context.Context, arg UpdateChatStatusPara
 		&i.Score,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Department,
+		&i.EntryPoint,
+		&i.PersonaSlug,
 	)
 	return i, err
 }
@@ -14,11 +14,11 @@ import (
 
 const createChunk = `-- name: CreateChunk :one
 INSERT INTO chunks (
-  source_id, source_path, source_filename, source_mime, source_page, department, language, text, embedding_vector, embedding_json, chunk_hash, created_by, status
+  source_id, source_path, source_filename, source_mime, source_page, department, language, text, embedding_vector, embedding_json, chunk_hash, created_by, status, heading_path, chunk_external_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13, 'ready')
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13, 'ready'), $14, COALESCE($15::uuid, gen_random_uuid())
 )
-RETURNING chunk_internal_id, chunk_external_id, source_id, source_path, source_filename, source_mime, source_page, department, language, text, text_tsv, embedding_vector, embedding_json, chunk_hash, created_at, created_by, status
+RETURNING chunk_internal_id, chunk_external_id, source_id, source_path, source_filename, source_mime, source_page, department, language, text, text_tsv, embedding_vector, embedding_json, chunk_hash, created_at, created_by, status, heading_path
 `
 
 type CreateChunkParams struct {
@@ -30,11 +30,13 @@ type CreateChunkParams struct {
 	Department      pgtype.Text `json:"department"`
 	Language        pgtype.Text `json:"language"`
 	Text            string      `json:"text"`
-	EmbeddingVector []byte      `json:"embedding_vector"`
+	EmbeddingVector interface{} `json:"embedding_vector"`
 	EmbeddingJson   []byte      `json:"embedding_json"`
 	ChunkHash       pgtype.Text `json:"chunk_hash"`
 	CreatedBy       pgtype.UUID `json:"created_by"`
 	Column13        interface{} `json:"column_13"`
+	HeadingPath     pgtype.Text `json:"heading_path"`
+	ChunkExternalID pgtype.UUID `json:"chunk_external_id"`
 }
 
 func (q *Queries) CreateChunk(ctx context.Context, arg CreateChunkParams) (Chunk, error) {
@@ -52,6 +54,8 @@ func (q *Queries) CreateChunk(ctx context.Context, arg CreateChunkParams) (Chunk
 		arg.ChunkHash,
 		arg.CreatedBy,
 		arg.Column13,
+		arg.HeadingPath,
+		arg.ChunkExternalID,
 	)
 	var i Chunk
 	err := row.Scan(
@@ -72,6 +76,7 @@ func (q *Queries) CreateChunk(ctx context.Context, arg CreateChunkParams) (Chunk
 		&i.CreatedAt,
 		&i.CreatedBy,
 		&i.Status,
+		&i.HeadingPath,
 	)
 	return i, err
 }
@@ -86,8 +91,18 @@ func (q *Queries) DeleteChunk(ctx context.Context, chunkExternalID uuid.UUID) er
 	return err
 }
 
+const deleteChunksBySource = `-- name: DeleteChunksBySource :exec
+DELETE FROM chunks
+WHERE source_id = $1
+`
+
+func (q *Queries) DeleteChunksBySource(ctx context.Context, sourceID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteChunksBySource, sourceID)
+	return err
+}
+
 const getChunkByID = `-- name: GetChunkByID :one
-SELECT chunk_internal_id, chunk_external_id, source_id, source_path, source_filename, source_mime, source_page, department, language, text, text_tsv, embedding_vector, embedding_json, chunk_hash, created_at, created_by, status FROM chunks
+SELECT chunk_internal_id, chunk_external_id, source_id, source_path, source_filename, source_mime, source_page, department, language, text, text_tsv, embedding_vector, embedding_json, chunk_hash, created_at, created_by, status, heading_path FROM chunks
 WHERE chunk_external_id = $1 LIMIT 1
 `
 
@@ -112,12 +127,75 @@ func (q *Queries) GetChunkByID(ctx context.Context, chunkExternalID uuid.UUID) (
 		&i.CreatedAt,
 		&i.CreatedBy,
 		&i.Status,
+		&i.HeadingPath,
 	)
 	return i, err
 }
 
+const listChunkHashesBySource = `-- name: ListChunkHashesBySource :many
+SELECT chunk_external_id, chunk_hash, status FROM chunks
+WHERE source_id = $1
+`
+
+type ListChunkHashesBySourceRow struct {
+	ChunkExternalID uuid.UUID   `json:"chunk_external_id"`
+	ChunkHash       pgtype.Text `json:"chunk_hash"`
+	Status          pgtype.Text `json:"status"`
+}
+
+func (q *Queries) ListChunkHashesBySource(ctx context.Context, sourceID pgtype.UUID) ([]ListChunkHashesBySourceRow, error) {
+	rows, err := q.db.Query(ctx, listChunkHashesBySource, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChunkHashesBySourceRow
+	for rows.Next() {
+		var i ListChunkHashesBySourceRow
+		if err := rows.Scan(&i.ChunkExternalID, &i.ChunkHash, &i.Status); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChunkHashesBySourcePath = `-- name: ListChunkHashesBySourcePath :many
+SELECT chunk_external_id, chunk_hash, status FROM chunks
+WHERE source_id IS NULL AND source_path = $1
+`
+
+type ListChunkHashesBySourcePathRow struct {
+	ChunkExternalID uuid.UUID   `json:"chunk_external_id"`
+	ChunkHash       pgtype.Text `json:"chunk_hash"`
+	Status          pgtype.Text `json:"status"`
+}
+
+func (q *Queries) ListChunkHashesBySourcePath(ctx context.Context, sourcePath pgtype.Text) ([]ListChunkHashesBySourcePathRow, error) {
+	rows, err := q.db.Query(ctx, listChunkHashesBySourcePath, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChunkHashesBySourcePathRow
+	for rows.Next() {
+		var i ListChunkHashesBySourcePathRow
+		if err := rows.Scan(&i.ChunkExternalID, &i.ChunkHash, &i.Status); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listChunksBySource = `-- name: ListChunksBySource :many
-SELECT chunk_internal_id, chunk_external_id, source_id, source_path, source_filename, source_mime, source_page, department, language, text, text_tsv, embedding_vector, embedding_json, chunk_hash, created_at, created_by, status FROM chunks
+SELECT chunk_internal_id, chunk_external_id, source_id, source_path, source_filename, source_mime, source_page, department, language, text, text_tsv, embedding_vector, embedding_json, chunk_hash, created_at, created_by, status, heading_path FROM chunks
 WHERE source_id = $1
 ORDER BY created_at DESC
 `
@@ -149,6 +227,7 @@ func (q *Queries) ListChunksBySource(ctx context.Context, sourceID pgtype.UUID)
 			&i.CreatedAt,
 			&i.CreatedBy,
 			&i.Status,
+			&i.HeadingPath,
 		); err != nil {
 			return nil, err
 		}
@@ -207,9 +286,9 @@ WHERE chunk_external_id = $1
 `
 
 type UpdateChunkEmbeddingParams struct {
-	ChunkExternalID uuid.UUID `json:"chunk_external_id"`
-	EmbeddingVector []byte    `json:"embedding_vector"`
-	EmbeddingJson   []byte    `json:"embedding_json"`
+	ChunkExternalID uuid.UUID   `json:"chunk_external_id"`
+	EmbeddingVector interface{} `json:"embedding_vector"`
+	EmbeddingJson   []byte      `json:"embedding_json"`
 }
 
 func (q *Queries) UpdateChunkEmbedding(ctx context.Context, arg UpdateChunkEmbeddingParams) error {
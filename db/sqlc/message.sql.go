@@ -218,6 +218,52 @@ func (q *Queries) GetMessage(ctx context.Context, messageExternalID uuid.UUID) (
 	return i, err
 }
 
+const getQuestionBeforeMessage = `-- name: GetQuestionBeforeMessage :one
+SELECT message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at
+FROM messages
+WHERE chat_external_id = $1
+  AND created_at < $2
+  AND sender_external_id = (SELECT user_external_id FROM chats WHERE chats.chat_external_id = $1)
+  AND NOT is_system_message
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetQuestionBeforeMessageParams struct {
+	ChatExternalID uuid.UUID        `json:"chat_external_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type GetQuestionBeforeMessageRow struct {
+	MessageID         pgtype.Int8      `json:"message_id"`
+	MessageExternalID uuid.UUID        `json:"message_external_id"`
+	ChatExternalID    uuid.UUID        `json:"chat_external_id"`
+	SenderExternalID  uuid.UUID        `json:"sender_external_id"`
+	Content           string           `json:"content"`
+	IsSystemMessage   bool             `json:"is_system_message"`
+	IsAdminMessage    bool             `json:"is_admin_message"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
+// The message an answer replies to: the chat user's latest one before it.
+func (q *Queries) GetQuestionBeforeMessage(ctx context.Context, arg GetQuestionBeforeMessageParams) (GetQuestionBeforeMessageRow, error) {
+	row := q.db.QueryRow(ctx, getQuestionBeforeMessage, arg.ChatExternalID, arg.CreatedAt)
+	var i GetQuestionBeforeMessageRow
+	err := row.Scan(
+		&i.MessageID,
+		&i.MessageExternalID,
+		&i.ChatExternalID,
+		&i.SenderExternalID,
+		&i.Content,
+		&i.IsSystemMessage,
+		&i.IsAdminMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const listMessagesByChat = `-- name: ListMessagesByChat :many
 SELECT message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at
 FROM messages
@@ -339,6 +385,67 @@ func (q *Queries) ListMessagesByChatSince(ctx context.Context, arg ListMessagesB
 	return items, nil
 }
 
+const listMessagesBeforeMessage = `-- name: ListMessagesBeforeMessage :many
+SELECT message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at
+FROM messages
+WHERE chat_external_id = $1
+  AND (created_at, message_id) < (
+    SELECT created_at, message_id FROM messages WHERE message_external_id = $2
+  )
+ORDER BY created_at DESC, message_id DESC
+LIMIT $3
+`
+
+type ListMessagesBeforeMessageParams struct {
+	ChatExternalID    uuid.UUID `json:"chat_external_id"`
+	MessageExternalID uuid.UUID `json:"message_external_id"`
+	Limit             int32     `json:"limit"`
+}
+
+type ListMessagesBeforeMessageRow struct {
+	MessageID         pgtype.Int8      `json:"message_id"`
+	MessageExternalID uuid.UUID        `json:"message_external_id"`
+	ChatExternalID    uuid.UUID        `json:"chat_external_id"`
+	SenderExternalID  uuid.UUID        `json:"sender_external_id"`
+	Content           string           `json:"content"`
+	IsSystemMessage   bool             `json:"is_system_message"`
+	IsAdminMessage    bool             `json:"is_admin_message"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
+// The messages sent before the given one, newest first. message_id breaks
+// ties between messages stored within the same clock tick.
+func (q *Queries) ListMessagesBeforeMessage(ctx context.Context, arg ListMessagesBeforeMessageParams) ([]ListMessagesBeforeMessageRow, error) {
+	rows, err := q.db.Query(ctx, listMessagesBeforeMessage, arg.ChatExternalID, arg.MessageExternalID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMessagesBeforeMessageRow
+	for rows.Next() {
+		var i ListMessagesBeforeMessageRow
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.MessageExternalID,
+			&i.ChatExternalID,
+			&i.SenderExternalID,
+			&i.Content,
+			&i.IsSystemMessage,
+			&i.IsAdminMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRecentMessagesByChat = `-- name: ListRecentMessagesByChat :many
 SELECT message_id, message_external_id, chat_external_id, sender_external_id, content, is_system_message, is_admin_message, created_at, updated_at
 FROM messages
@@ -467,3 +574,19 @@ func (q *Queries) MarkMessageAsSystem(ctx context.Context, messageExternalID uui
 	)
 	return i, err
 }
+
+const setMessagePromptTemplate = `-- name: SetMessagePromptTemplate :exec
+UPDATE messages
+SET prompt_template_id = $2
+WHERE message_external_id = $1
+`
+
+type SetMessagePromptTemplateParams struct {
+	MessageExternalID uuid.UUID   `json:"message_external_id"`
+	PromptTemplateID  pgtype.UUID `json:"prompt_template_id"`
+}
+
+func (q *Queries) SetMessagePromptTemplate(ctx context.Context, arg SetMessagePromptTemplateParams) error {
+	_, err := q.db.Exec(ctx, setMessagePromptTemplate, arg.MessageExternalID, arg.PromptTemplateID)
+	return err
+}
@@ -64,6 +64,7 @@ const (
 	ChatStatusTypeOpen    ChatStatusType = "open"
 	ChatStatusTypePending ChatStatusType = "pending"
 	ChatStatusTypeClosed  ChatStatusType = "closed"
+	ChatStatusTypeWaiting ChatStatusType = "waiting"
 )
 
 func (e *ChatStatusType) Scan(src interface{}) error {
@@ -201,6 +202,27 @@ type AiKnowledge struct {
 	EmbeddingJson       []byte      `json:"embedding_json"`
 	CreatedAt           time.Time   `json:"created_at"`
 	CreatedBy           pgtype.UUID `json:"created_by"`
+	ChatExternalID      pgtype.UUID `json:"chat_external_id"`
+	QuestionMessageID   pgtype.UUID `json:"question_message_id"`
+	AnswerMessageID     pgtype.UUID `json:"answer_message_id"`
+}
+
+type BotPersona struct {
+	PersonaID            pgtype.Int8        `json:"persona_id"`
+	Slug                 string             `json:"slug"`
+	DisplayName          string             `json:"display_name"`
+	AvatarUrl            pgtype.Text        `json:"avatar_url"`
+	PromptName           string             `json:"prompt_name"`
+	Departments          []string           `json:"departments"`
+	SourceIds            []uuid.UUID        `json:"source_ids"`
+	ChatModel            pgtype.Text        `json:"chat_model"`
+	Temperature          pgtype.Float8      `json:"temperature"`
+	HandoffMinSimilarity pgtype.Float8      `json:"handoff_min_similarity"`
+	HandoffPhrases       []string           `json:"handoff_phrases"`
+	LabelKeywords        []string           `json:"label_keywords"`
+	EntryPoints          []string           `json:"entry_points"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Chat struct {
@@ -213,6 +235,20 @@ type Chat struct {
 	Score           pgtype.Int8      `json:"score"`
 	CreatedAt       pgtype.Timestamp `json:"created_at"`
 	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+	Department      pgtype.Text      `json:"department"`
+	EntryPoint      pgtype.Text      `json:"entry_point"`
+	PersonaSlug     pgtype.Text      `json:"persona_slug"`
+}
+
+type ChatHandoff struct {
+	HandoffID         pgtype.Int8        `json:"handoff_id"`
+	HandoffExternalID uuid.UUID          `json:"handoff_external_id"`
+	ChatExternalID    uuid.UUID          `json:"chat_external_id"`
+	MessageExternalID pgtype.UUID        `json:"message_external_id"`
+	Reason            string             `json:"reason"`
+	Detail            pgtype.Text        `json:"detail"`
+	Similarity        pgtype.Float8      `json:"similarity"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 }
 
 type Chunk struct {
@@ -227,12 +263,72 @@ type Chunk struct {
 	Language        pgtype.Text `json:"language"`
 	Text            string      `json:"text"`
 	TextTsv         interface{} `json:"text_tsv"`
-	EmbeddingVector []byte      `json:"embedding_vector"`
+	EmbeddingVector interface{} `json:"embedding_vector"`
 	EmbeddingJson   []byte      `json:"embedding_json"`
 	ChunkHash       pgtype.Text `json:"chunk_hash"`
 	CreatedAt       time.Time   `json:"created_at"`
 	CreatedBy       pgtype.UUID `json:"created_by"`
 	Status          pgtype.Text `json:"status"`
+	HeadingPath     pgtype.Text `json:"heading_path"`
+}
+
+type ChunkFeedback struct {
+	ChunkExternalID  uuid.UUID          `json:"chunk_external_id"`
+	FeedbackScore    int64              `json:"feedback_score"`
+	FeedbackWeight   int64              `json:"feedback_weight"`
+	RatedAnswers     int32              `json:"rated_answers"`
+	UpvotedAnswers   int32              `json:"upvoted_answers"`
+	DownvotedAnswers int32              `json:"downvoted_answers"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Department struct {
+	DepartmentID pgtype.Int8        `json:"department_id"`
+	Slug         string             `json:"slug"`
+	Name         string             `json:"name"`
+	Keywords     []string           `json:"keywords"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type EmbeddingCache struct {
+	ContentHash string             `json:"content_hash"`
+	Model       string             `json:"model"`
+	Embedding   []float64          `json:"embedding"`
+	Hits        int64              `json:"hits"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+}
+
+type FollowUpTicket struct {
+	TicketID         pgtype.Int8        `json:"ticket_id"`
+	TicketExternalID uuid.UUID          `json:"ticket_external_id"`
+	ChatExternalID   uuid.UUID          `json:"chat_external_id"`
+	UserExternalID   uuid.UUID          `json:"user_external_id"`
+	Department       pgtype.Text        `json:"department"`
+	Subject          string             `json:"subject"`
+	Details          pgtype.Text        `json:"details"`
+	Status           string             `json:"status"`
+	ResolvedBy       pgtype.UUID        `json:"resolved_by"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	ResolvedAt       pgtype.Timestamptz `json:"resolved_at"`
+}
+
+type Job struct {
+	JobID         pgtype.Int8        `json:"job_id"`
+	JobExternalID uuid.UUID          `json:"job_external_id"`
+	Kind          string             `json:"kind"`
+	Payload       []byte             `json:"payload"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	MaxAttempts   int32              `json:"max_attempts"`
+	RunAt         pgtype.Timestamptz `json:"run_at"`
+	LockedAt      pgtype.Timestamptz `json:"locked_at"`
+	LockedBy      pgtype.Text        `json:"locked_by"`
+	LastError     pgtype.Text        `json:"last_error"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	FinishedAt    pgtype.Timestamptz `json:"finished_at"`
 }
 
 type Message struct {
@@ -246,6 +342,7 @@ type Message struct {
 	CreatedAt         pgtype.Timestamp `json:"created_at"`
 	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
 	MessageType       MessageType      `json:"message_type"`
+	PromptTemplateID  pgtype.UUID      `json:"prompt_template_id"`
 }
 
 type MessageAttachment struct {
@@ -262,6 +359,18 @@ type MessageAttachment struct {
 	CreatedAt            pgtype.Timestamp `json:"created_at"`
 }
 
+type MessageCitation struct {
+	CitationID         pgtype.Int8      `json:"citation_id"`
+	CitationExternalID uuid.UUID        `json:"citation_external_id"`
+	MessageExternalID  uuid.UUID        `json:"message_external_id"`
+	ChunkExternalID    pgtype.UUID      `json:"chunk_external_id"`
+	SourceID           pgtype.UUID      `json:"source_id"`
+	SourceFilename     pgtype.Text      `json:"source_filename"`
+	SourcePage         pgtype.Int4      `json:"source_page"`
+	Position           int32            `json:"position"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+}
+
 type MessageReaction struct {
 	ReactionID         pgtype.Int8      `json:"reaction_id"`
 	ReactionExternalID uuid.UUID        `json:"reaction_external_id"`
@@ -272,6 +381,31 @@ type MessageReaction struct {
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 }
 
+type MessageToolCall struct {
+	ToolCallID         pgtype.Int8        `json:"tool_call_id"`
+	ToolCallExternalID uuid.UUID          `json:"tool_call_external_id"`
+	MessageExternalID  uuid.UUID          `json:"message_external_id"`
+	ChatExternalID     uuid.UUID          `json:"chat_external_id"`
+	CallID             string             `json:"call_id"`
+	ToolName           string             `json:"tool_name"`
+	Arguments          []byte             `json:"arguments"`
+	Result             string             `json:"result"`
+	IsError            bool               `json:"is_error"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
+type PromptTemplate struct {
+	TemplateID         pgtype.Int8        `json:"template_id"`
+	TemplateExternalID uuid.UUID          `json:"template_external_id"`
+	Name               string             `json:"name"`
+	Version            int32              `json:"version"`
+	SystemPrompt       string             `json:"system_prompt"`
+	ContextPrompt      string             `json:"context_prompt"`
+	IsActive           bool               `json:"is_active"`
+	CreatedBy          pgtype.UUID        `json:"created_by"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
 type Session struct {
 	SessionID         pgtype.Int8 `json:"session_id"`
 	SessionExternalID uuid.UUID   `json:"session_external_id"`
@@ -297,6 +431,8 @@ type SourceFile struct {
 	UploadedAt       pgtype.Timestamptz `json:"uploaded_at"`
 	ProcessedAt      pgtype.Timestamptz `json:"processed_at"`
 	Status           pgtype.Text        `json:"status"`
+	ErrorMessage     pgtype.Text        `json:"error_message"`
+	Department       pgtype.Text        `json:"department"`
 }
 
 type User struct {
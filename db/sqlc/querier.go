@@ -41,12 +41,46 @@ type Querier interface {
 	GetTopChatsByScore(ctx context.Context, arg GetTopChatsByScoreParams) ([]Chat, error)
 	ListChats(ctx context.Context, arg ListChatsParams) ([]Chat, error)
 	ListClosedChats(ctx context.Context, arg ListClosedChatsParams) ([]Chat, error)
+	ListDepartmentQueue(ctx context.Context, arg ListDepartmentQueueParams) ([]Chat, error)
 	ListOpenChats(ctx context.Context, arg ListOpenChatsParams) ([]Chat, error)
 	ListPendingChats(ctx context.Context, arg ListPendingChatsParams) ([]Chat, error)
+	SetChatDepartment(ctx context.Context, arg SetChatDepartmentParams) (Chat, error)
+	SetChatPersona(ctx context.Context, arg SetChatPersonaParams) (Chat, error)
 	UpdateChat(ctx context.Context, arg UpdateChatParams) (Chat, error)
 	UpdateChatScore(ctx context.Context, arg UpdateChatScoreParams) (Chat, error)
 	UpdateChatStatus(ctx context.Context, arg UpdateChatStatusParams) (Chat, error)
 
+	// Handoff
+	CreateChatHandoff(ctx context.Context, arg CreateChatHandoffParams) (ChatHandoff, error)
+	GetLatestHandoffByChat(ctx context.Context, chatExternalID uuid.UUID) (ChatHandoff, error)
+	ListHandoffsByChat(ctx context.Context, chatExternalID uuid.UUID) ([]ChatHandoff, error)
+
+	// FollowUpTicket
+	CreateFollowUpTicket(ctx context.Context, arg CreateFollowUpTicketParams) (FollowUpTicket, error)
+	ListFollowUpTickets(ctx context.Context, arg ListFollowUpTicketsParams) ([]FollowUpTicket, error)
+	ResolveFollowUpTicket(ctx context.Context, arg ResolveFollowUpTicketParams) (FollowUpTicket, error)
+
+	// Department
+	DeleteDepartment(ctx context.Context, slug string) error
+	GetDepartment(ctx context.Context, slug string) (Department, error)
+	ListDepartments(ctx context.Context) ([]Department, error)
+	UpsertDepartment(ctx context.Context, arg UpsertDepartmentParams) (Department, error)
+
+	// BotPersona
+	DeleteBotPersona(ctx context.Context, slug string) error
+	GetBotPersona(ctx context.Context, slug string) (BotPersona, error)
+	ListBotPersonas(ctx context.Context) ([]BotPersona, error)
+	UpsertBotPersona(ctx context.Context, arg UpsertBotPersonaParams) (BotPersona, error)
+
+	// PromptTemplate
+	ActivatePromptTemplate(ctx context.Context, templateExternalID uuid.UUID) (PromptTemplate, error)
+	CreatePromptTemplate(ctx context.Context, arg CreatePromptTemplateParams) (PromptTemplate, error)
+	DeactivatePromptTemplates(ctx context.Context, name string) error
+	DeletePromptTemplate(ctx context.Context, templateExternalID uuid.UUID) (int64, error)
+	GetActivePromptTemplate(ctx context.Context, name string) (PromptTemplate, error)
+	GetPromptTemplate(ctx context.Context, templateExternalID uuid.UUID) (PromptTemplate, error)
+	ListPromptTemplates(ctx context.Context, name string) ([]PromptTemplate, error)
+
 	// Message
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (CreateMessageRow, error)
 	EditMessage(ctx context.Context, arg EditMessageParams) (EditMessageRow, error)
@@ -54,11 +88,14 @@ type Querier interface {
 	DeleteMessagesByChat(ctx context.Context, chatExternalID uuid.UUID) error
 	GetMessage(ctx context.Context, messageExternalID uuid.UUID) (GetMessageRow, error)
 	GetLastMessageByChat(ctx context.Context, chatExternalID uuid.UUID) (GetLastMessageByChatRow, error)
+	GetQuestionBeforeMessage(ctx context.Context, arg GetQuestionBeforeMessageParams) (GetQuestionBeforeMessageRow, error)
+	ListMessagesBeforeMessage(ctx context.Context, arg ListMessagesBeforeMessageParams) ([]ListMessagesBeforeMessageRow, error)
 	ListMessagesByChat(ctx context.Context, arg ListMessagesByChatParams) ([]ListMessagesByChatRow, error)
 	ListMessagesByChatSince(ctx context.Context, arg ListMessagesByChatSinceParams) ([]ListMessagesByChatSinceRow, error)
 	ListRecentMessagesByChat(ctx context.Context, arg ListRecentMessagesByChatParams) ([]ListRecentMessagesByChatRow, error)
 	MarkMessageAsAdmin(ctx context.Context, messageExternalID uuid.UUID) (MarkMessageAsAdminRow, error)
 	MarkMessageAsSystem(ctx context.Context, messageExternalID uuid.UUID) (MarkMessageAsSystemRow, error)
+	SetMessagePromptTemplate(ctx context.Context, arg SetMessagePromptTemplateParams) error
 	AddOrUpdateReaction(ctx context.Context, arg AddOrUpdateReactionParams) (MessageReaction, error)
 	InsertReactionWithWeight(ctx context.Context, arg InsertReactionWithWeightParams) (MessageReaction, error)
 	RemoveReaction(ctx context.Context, arg RemoveReactionParams) error
@@ -81,22 +118,46 @@ type Querier interface {
 	ListAttachmentsByChat(ctx context.Context, arg ListAttachmentsByChatParams) ([]MessageAttachment, error)
 	ListAttachmentsByMessage(ctx context.Context, arg ListAttachmentsByMessageParams) ([]MessageAttachment, error)
 
+	// Citations
+	CreateMessageCitation(ctx context.Context, arg CreateMessageCitationParams) (MessageCitation, error)
+	ListCitationsByMessage(ctx context.Context, messageExternalID uuid.UUID) ([]MessageCitation, error)
+
+	// ToolCall
+	CreateMessageToolCall(ctx context.Context, arg CreateMessageToolCallParams) (MessageToolCall, error)
+	ListToolCallsByChat(ctx context.Context, chatExternalID uuid.UUID) ([]MessageToolCall, error)
+
 	// Knowledge
 	CreateKnowledge(ctx context.Context, arg CreateKnowledgeParams) (AiKnowledge, error)
 	DeleteKnowledge(ctx context.Context, knowledgeExternalID uuid.UUID) error
+	GetKnowledgeByAnswerMessage(ctx context.Context, answerMessageID pgtype.UUID) (AiKnowledge, error)
 	GetKnowledgeByID(ctx context.Context, knowledgeExternalID uuid.UUID) (AiKnowledge, error)
+	ListKnowledge(ctx context.Context, arg ListKnowledgeParams) ([]AiKnowledge, error)
 	SearchKnowledgeFulltext(ctx context.Context, arg SearchKnowledgeFulltextParams) ([]SearchKnowledgeFulltextRow, error)
 	UpdateKnowledgeEmbedding(ctx context.Context, arg UpdateKnowledgeEmbeddingParams) error
 
 	// Chunk
 	CreateChunk(ctx context.Context, arg CreateChunkParams) (Chunk, error)
 	DeleteChunk(ctx context.Context, chunkExternalID uuid.UUID) error
+	DeleteChunksBySource(ctx context.Context, sourceID pgtype.UUID) error
 	GetChunkByID(ctx context.Context, chunkExternalID uuid.UUID) (Chunk, error)
+	ListChunkHashesBySource(ctx context.Context, sourceID pgtype.UUID) ([]ListChunkHashesBySourceRow, error)
+	ListChunkHashesBySourcePath(ctx context.Context, sourcePath pgtype.Text) ([]ListChunkHashesBySourcePathRow, error)
 	ListChunksBySource(ctx context.Context, sourceID pgtype.UUID) ([]Chunk, error)
 	SearchChunksFulltext(ctx context.Context, arg SearchChunksFulltextParams) ([]SearchChunksFulltextRow, error)
 	UpdateChunkEmbedding(ctx context.Context, arg UpdateChunkEmbeddingParams) error
 	UpdateChunkStatus(ctx context.Context, arg UpdateChunkStatusParams) error
 
+	// ChunkFeedback
+	ListDownvotedChunks(ctx context.Context, arg ListDownvotedChunksParams) ([]ListDownvotedChunksRow, error)
+	ListDownvotedSources(ctx context.Context, rowLimit int32) ([]ListDownvotedSourcesRow, error)
+
+	// EmbeddingCache
+	DeleteUnusedEmbeddings(ctx context.Context, lastUsedAt pgtype.Timestamptz) error
+	GetCachedEmbeddings(ctx context.Context, arg GetCachedEmbeddingsParams) ([]GetCachedEmbeddingsRow, error)
+	GetEmbeddingCacheStats(ctx context.Context) ([]GetEmbeddingCacheStatsRow, error)
+	InsertCachedEmbedding(ctx context.Context, arg InsertCachedEmbeddingParams) error
+	TouchCachedEmbeddings(ctx context.Context, arg TouchCachedEmbeddingsParams) error
+
 	// Session
 	BlockSession(ctx context.Context, sessionExternalID uuid.UUID) error
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
@@ -109,9 +170,26 @@ type Querier interface {
 	GetSessionByRefreshToken(ctx context.Context, refreshToken string) (Session, error)
 
 	// SourceFile
+	ClaimSourceForProcessing(ctx context.Context, sourceExternalID uuid.UUID) (SourceFile, error)
 	CreateSourceFile(ctx context.Context, arg CreateSourceFileParams) (SourceFile, error)
+	DeleteSourceFile(ctx context.Context, sourceExternalID uuid.UUID) error
 	GetSourceByExternalID(ctx context.Context, sourceExternalID uuid.UUID) (SourceFile, error)
 	ListUploadedSources(ctx context.Context, limit int32) ([]SourceFile, error)
-	MarkSourceProcessed(ctx context.Context, sourceID pgtype.Int8) error
+	MarkSourceFailed(ctx context.Context, arg MarkSourceFailedParams) error
+	MarkSourceProcessed(ctx context.Context, sourceExternalID uuid.UUID) error
+	ResetSourceStatus(ctx context.Context, sourceExternalID uuid.UUID) (SourceFile, error)
 	ListDocumentsByUser(ctx context.Context, uploadedBy pgtype.UUID) ([]ListDocumentsByUserRow, error)
+
+	// Job
+	ClaimJob(ctx context.Context, arg ClaimJobParams) (Job, error)
+	CompleteJob(ctx context.Context, jobExternalID uuid.UUID) error
+	CountJobsByStatus(ctx context.Context) ([]CountJobsByStatusRow, error)
+	DeadLetterJob(ctx context.Context, arg DeadLetterJobParams) error
+	DeleteFinishedJobs(ctx context.Context, finishedAt pgtype.Timestamptz) error
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
+	GetJob(ctx context.Context, jobExternalID uuid.UUID) (Job, error)
+	ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error)
+	RequeueStaleJobs(ctx context.Context, lockedAt pgtype.Timestamptz) error
+	RetryDeadJob(ctx context.Context, jobExternalID uuid.UUID) (Job, error)
+	RetryJob(ctx context.Context, arg RetryJobParams) error
 }
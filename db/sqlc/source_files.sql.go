@@ -12,13 +12,42 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimSourceForProcessing = `-- name: ClaimSourceForProcessing :one
+UPDATE source_files
+SET status = 'processing',
+    error_message = NULL
+WHERE source_external_id = $1
+  AND status <> 'processed'
+RETURNING source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status, error_message, department
+`
+
+func (q *Queries) ClaimSourceForProcessing(ctx context.Context, sourceExternalID uuid.UUID) (SourceFile, error) {
+	row := q.db.QueryRow(ctx, claimSourceForProcessing, sourceExternalID)
+	var i SourceFile
+	err := row.Scan(
+		&i.SourceID,
+		&i.SourceExternalID,
+		&i.StorageKey,
+		&i.Filename,
+		&i.MimeType,
+		&i.SizeBytes,
+		&i.UploadedBy,
+		&i.UploadedAt,
+		&i.ProcessedAt,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.Department,
+	)
+	return i, err
+}
+
 const createSourceFile = `-- name: CreateSourceFile :one
 INSERT INTO source_files (
-  storage_key, filename, mime_type, size_bytes, uploaded_by
+  storage_key, filename, mime_type, size_bytes, uploaded_by, department
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6
 )
-RETURNING source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status
+RETURNING source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status, error_message, department
 `
 
 type CreateSourceFileParams struct {
@@ -27,6 +56,7 @@ type CreateSourceFileParams struct {
 	MimeType   pgtype.Text `json:"mime_type"`
 	SizeBytes  pgtype.Int8 `json:"size_bytes"`
 	UploadedBy pgtype.UUID `json:"uploaded_by"`
+	Department pgtype.Text `json:"department"`
 }
 
 func (q *Queries) CreateSourceFile(ctx context.Context, arg CreateSourceFileParams) (SourceFile, error) {
@@ -36,6 +66,7 @@ func (q *Queries) CreateSourceFile(ctx context.Context, arg CreateSourceFilePara
 		arg.MimeType,
 		arg.SizeBytes,
 		arg.UploadedBy,
+		arg.Department,
 	)
 	var i SourceFile
 	err := row.Scan(
@@ -49,12 +80,24 @@ func (q *Queries) CreateSourceFile(ctx context.Context, arg CreateSourceFilePara
 		&i.UploadedAt,
 		&i.ProcessedAt,
 		&i.Status,
+		&i.ErrorMessage,
+		&i.Department,
 	)
 	return i, err
 }
 
+const deleteSourceFile = `-- name: DeleteSourceFile :exec
+DELETE FROM source_files
+WHERE source_external_id = $1
+`
+
+func (q *Queries) DeleteSourceFile(ctx context.Context, sourceExternalID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteSourceFile, sourceExternalID)
+	return err
+}
+
 const getSourceByExternalID = `-- name: GetSourceByExternalID :one
-SELECT source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status FROM source_files
+SELECT source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status, error_message, department FROM source_files
 WHERE source_external_id = $1
 `
 
@@ -72,6 +115,8 @@ func (q *Queries) GetSourceByExternalID(ctx context.Context, sourceExternalID uu
 		&i.UploadedAt,
 		&i.ProcessedAt,
 		&i.Status,
+		&i.ErrorMessage,
+		&i.Department,
 	)
 	return i, err
 }
@@ -122,7 +167,7 @@ func (q *Queries) ListDocumentsByUser(ctx context.Context, uploadedBy pgtype.UUI
 }
 
 const listUploadedSources = `-- name: ListUploadedSources :many
-SELECT source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status
+SELECT source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status, error_message, department
 FROM source_files
 ORDER BY uploaded_at DESC
 LIMIT $1
@@ -148,6 +193,8 @@ func (q *Queries) ListUploadedSources(ctx context.Context, limit int32) ([]Sourc
 			&i.UploadedAt,
 			&i.ProcessedAt,
 			&i.Status,
+			&i.ErrorMessage,
+			&i.Department,
 		); err != nil {
 			return nil, err
 		}
@@ -159,14 +206,61 @@ func (q *Queries) ListUploadedSources(ctx context.Context, limit int32) ([]Sourc
 	return items, nil
 }
 
+const markSourceFailed = `-- name: MarkSourceFailed :exec
+UPDATE source_files
+SET status = 'failed',
+    error_message = $2
+WHERE source_external_id = $1
+`
+
+type MarkSourceFailedParams struct {
+	SourceExternalID uuid.UUID   `json:"source_external_id"`
+	ErrorMessage     pgtype.Text `json:"error_message"`
+}
+
+func (q *Queries) MarkSourceFailed(ctx context.Context, arg MarkSourceFailedParams) error {
+	_, err := q.db.Exec(ctx, markSourceFailed, arg.SourceExternalID, arg.ErrorMessage)
+	return err
+}
+
 const markSourceProcessed = `-- name: MarkSourceProcessed :exec
 UPDATE source_files
 SET processed_at = now(),
-    status = 'processed'
-WHERE source_id = $1
+    status = 'processed',
+    error_message = NULL
+WHERE source_external_id = $1
 `
 
-func (q *Queries) MarkSourceProcessed(ctx context.Context, sourceID pgtype.Int8) error {
-	_, err := q.db.Exec(ctx, markSourceProcessed, sourceID)
+func (q *Queries) MarkSourceProcessed(ctx context.Context, sourceExternalID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markSourceProcessed, sourceExternalID)
 	return err
 }
+
+const resetSourceStatus = `-- name: ResetSourceStatus :one
+UPDATE source_files
+SET status = 'uploaded',
+    error_message = NULL,
+    processed_at = NULL
+WHERE source_external_id = $1
+RETURNING source_id, source_external_id, storage_key, filename, mime_type, size_bytes, uploaded_by, uploaded_at, processed_at, status, error_message, department
+`
+
+func (q *Queries) ResetSourceStatus(ctx context.Context, sourceExternalID uuid.UUID) (SourceFile, error) {
+	row := q.db.QueryRow(ctx, resetSourceStatus, sourceExternalID)
+	var i SourceFile
+	err := row.Scan(
+		&i.SourceID,
+		&i.SourceExternalID,
+		&i.StorageKey,
+		&i.Filename,
+		&i.MimeType,
+		&i.SizeBytes,
+		&i.UploadedBy,
+		&i.UploadedAt,
+		&i.ProcessedAt,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.Department,
+	)
+	return i, err
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -31,12 +32,54 @@ type StartChatTxResult struct {
 	Message CreateMessageRow
 }
 
+type CreateBotMessageTxParams struct {
+	CreateMessageParams
+	Citations        []CreateMessageCitationParams
+	PromptTemplateID pgtype.UUID
+}
+
+type CreateBotMessageTxResult struct {
+	Message   CreateMessageRow
+	Citations []MessageCitation
+}
+
+type HandoffChatTxParams struct {
+	CreateMessageParams
+	Reason     string
+	Detail     pgtype.Text
+	Similarity pgtype.Float8
+}
+
+type HandoffChatTxResult struct {
+	Message CreateMessageRow
+	Chat    Chat
+	Handoff ChatHandoff
+}
+
+type LogToolCallTxParams struct {
+	CreateMessageParams
+	CallID    string
+	ToolName  string
+	Arguments []byte
+	Result    string
+	IsError   bool
+}
+
+type LogToolCallTxResult struct {
+	Message  CreateMessageRow
+	ToolCall MessageToolCall
+}
+
 type Store interface {
 	Querier
 	CreateChatTx(ctx context.Context, arg StartChatTxParams) (StartChatTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
 	ToggleReactionTx(ctx context.Context, arg ToggleReactionParams) (ToggleReactionRow, error)
 	InsertReactionTx(ctx context.Context, arg InsertReactionWithWeightParams) (MessageReaction, error)
+	CreateBotMessageTx(ctx context.Context, arg CreateBotMessageTxParams) (CreateBotMessageTxResult, error)
+	HandoffChatTx(ctx context.Context, arg HandoffChatTxParams) (HandoffChatTxResult, error)
+	LogToolCallTx(ctx context.Context, arg LogToolCallTxParams) (LogToolCallTxResult, error)
+	ActivatePromptTemplateTx(ctx context.Context, templateExternalID uuid.UUID) (PromptTemplate, error)
 }
 
 type SQLStore struct {
@@ -172,3 +215,129 @@ func (store *SQLStore) InsertReactionTx(ctx context.Context, arg InsertReactionW
 
 	return result, err
 }
+
+// CreateBotMessageTx stores a generated answer together with the chunks it
+// was grounded on, so a message never shows up without its citations.
+func (store *SQLStore) CreateBotMessageTx(ctx context.Context, arg CreateBotMessageTxParams) (CreateBotMessageTxResult, error) {
+	var result CreateBotMessageTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Message, err = q.CreateMessage(ctx, arg.CreateMessageParams)
+		if err != nil {
+			return fmt.Errorf("failed to create message: %w", err)
+		}
+
+		for _, c := range arg.Citations {
+			c.MessageExternalID = result.Message.MessageExternalID
+			citation, err := q.CreateMessageCitation(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to create citation: %w", err)
+			}
+			result.Citations = append(result.Citations, citation)
+		}
+
+		if arg.PromptTemplateID.Valid {
+			err = q.SetMessagePromptTemplate(ctx, SetMessagePromptTemplateParams{
+				MessageExternalID: result.Message.MessageExternalID,
+				PromptTemplateID:  arg.PromptTemplateID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record prompt template: %w", err)
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// LogToolCallTx records a tool the bot ran as a system message in the chat,
+// with the call's arguments and result attached for auditing.
+func (store *SQLStore) LogToolCallTx(ctx context.Context, arg LogToolCallTxParams) (LogToolCallTxResult, error) {
+	var result LogToolCallTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Message, err = q.CreateMessage(ctx, arg.CreateMessageParams)
+		if err != nil {
+			return fmt.Errorf("failed to create message: %w", err)
+		}
+
+		result.ToolCall, err = q.CreateMessageToolCall(ctx, CreateMessageToolCallParams{
+			MessageExternalID: result.Message.MessageExternalID,
+			ChatExternalID:    result.Message.ChatExternalID,
+			CallID:            arg.CallID,
+			ToolName:          arg.ToolName,
+			Arguments:         arg.Arguments,
+			Result:            arg.Result,
+			IsError:           arg.IsError,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record tool call: %w", err)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// HandoffChatTx posts the system message that tells the user a person will
+// take over, parks the chat in the waiting status and records why, so the
+// admin queue never shows a waiting chat without a reason.
+func (store *SQLStore) HandoffChatTx(ctx context.Context, arg HandoffChatTxParams) (HandoffChatTxResult, error) {
+	var result HandoffChatTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Message, err = q.CreateMessage(ctx, arg.CreateMessageParams)
+		if err != nil {
+			return fmt.Errorf("failed to create message: %w", err)
+		}
+
+		result.Chat, err = q.UpdateChatStatus(ctx, UpdateChatStatusParams{
+			ChatExternalID: arg.ChatExternalID,
+			Column2:        string(ChatStatusTypeWaiting),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update chat status: %w", err)
+		}
+
+		result.Handoff, err = q.CreateChatHandoff(ctx, CreateChatHandoffParams{
+			ChatExternalID:    arg.ChatExternalID,
+			MessageExternalID: pgtype.UUID{Bytes: result.Message.MessageExternalID, Valid: true},
+			Reason:            arg.Reason,
+			Detail:            arg.Detail,
+			Similarity:        arg.Similarity,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record handoff: %w", err)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// ActivatePromptTemplateTx makes one version the live one for its name,
+// retiring whichever version was live before.
+func (store *SQLStore) ActivatePromptTemplateTx(ctx context.Context, templateExternalID uuid.UUID) (PromptTemplate, error) {
+	var result PromptTemplate
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		template, err := q.GetPromptTemplate(ctx, templateExternalID)
+		if err != nil {
+			return err
+		}
+		if err := q.DeactivatePromptTemplates(ctx, template.Name); err != nil {
+			return fmt.Errorf("failed to deactivate prompt templates: %w", err)
+		}
+		result, err = q.ActivatePromptTemplate(ctx, templateExternalID)
+		if err != nil {
+			return fmt.Errorf("failed to activate prompt template: %w", err)
+		}
+		return nil
+	})
+
+	return result, err
+}
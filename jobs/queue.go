@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+
+	DefaultMaxAttempts = 5
+)
+
+// Handler runs one job. Returning an error schedules a retry with backoff
+// until the job runs out of attempts; wrap it with Permanent to dead-letter
+// the job straight away.
+type Handler func(ctx context.Context, job db.Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. a malformed payload.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+type Queue struct {
+	store *db.SQLStore
+}
+
+func NewQueue(store *db.SQLStore) *Queue {
+	return &Queue{store: store}
+}
+
+// Enqueue stores a job of the given kind; payload is encoded as JSON. The
+// job becomes visible to workers as soon as the insert commits.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload interface{}) (db.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return db.Job{}, err
+	}
+	return q.store.Querier.EnqueueJob(ctx, db.EnqueueJobParams{
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+}
@@ -0,0 +1,213 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	defaultWorkers      = 2
+	defaultPollInterval = 2 * time.Second
+
+	jobTimeout        = 15 * time.Minute
+	staleAfter        = 2 * jobTimeout
+	keepFinishedFor   = 7 * 24 * time.Hour
+	maintenancePeriod = time.Minute
+
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Worker claims jobs from the jobs table with FOR UPDATE SKIP LOCKED, so any
+// number of API instances can run one without handing out a job twice.
+type Worker struct {
+	store        *db.SQLStore
+	id           string
+	concurrency  int
+	pollInterval time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewWorker(config util.Config, store *db.SQLStore) *Worker {
+	host, _ := os.Hostname()
+	w := &Worker{
+		store:        store,
+		id:           fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8]),
+		concurrency:  config.JobWorkers,
+		pollInterval: config.JobPollInterval,
+		handlers:     make(map[string]Handler),
+	}
+	if w.concurrency <= 0 {
+		w.concurrency = defaultWorkers
+	}
+	if w.pollInterval <= 0 {
+		w.pollInterval = defaultPollInterval
+	}
+	return w
+}
+
+// Register must be called before Start. Only registered kinds are claimed.
+func (w *Worker) Register(kind string, h Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[kind] = h
+}
+
+func (w *Worker) kinds() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	kinds := make([]string, 0, len(w.handlers))
+	for k := range w.handlers {
+		kinds = append(kinds, k)
+	}
+	return kinds
+}
+
+func (w *Worker) handler(kind string) (Handler, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	h, ok := w.handlers[kind]
+	return h, ok
+}
+
+// Start blocks until ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) {
+	fmt.Printf("Job worker %s starting with %d goroutines\n", w.id, w.concurrency)
+	var wg sync.WaitGroup
+	wg.Add(w.concurrency + 1)
+	go func() {
+		defer wg.Done()
+		w.maintain(ctx)
+	}()
+	for i := 0; i < w.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		claimed, err := w.runOne(ctx)
+		if err != nil {
+			fmt.Printf("Job worker error: %v\n", err)
+		}
+		if claimed {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+func (w *Worker) runOne(ctx context.Context) (bool, error) {
+	kinds := w.kinds()
+	if len(kinds) == 0 {
+		return false, nil
+	}
+	job, err := w.store.Querier.ClaimJob(ctx, db.ClaimJobParams{
+		WorkerID: pgtype.Text{String: w.id, Valid: true},
+		Kinds:    kinds,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	runErr := w.run(ctx, job)
+	return true, w.finish(ctx, job, runErr)
+}
+
+func (w *Worker) run(ctx context.Context, job db.Job) (err error) {
+	h, ok := w.handler(job.Kind)
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+	return h(jobCtx, job)
+}
+
+func (w *Worker) finish(ctx context.Context, job db.Job, runErr error) error {
+	if runErr == nil {
+		return w.store.Querier.CompleteJob(ctx, job.JobExternalID)
+	}
+
+	lastError := pgtype.Text{String: runErr.Error(), Valid: true}
+	if isPermanent(runErr) || job.Attempts >= job.MaxAttempts {
+		fmt.Printf("Job %s (%s) dead after %d attempts: %v\n", job.JobExternalID, job.Kind, job.Attempts, runErr)
+		return w.store.Querier.DeadLetterJob(ctx, db.DeadLetterJobParams{
+			JobExternalID: job.JobExternalID,
+			LastError:     lastError,
+		})
+	}
+
+	delay := backoff(int(job.Attempts))
+	fmt.Printf("Job %s (%s) attempt %d failed, retrying in %s: %v\n", job.JobExternalID, job.Kind, job.Attempts, delay, runErr)
+	return w.store.Querier.RetryJob(ctx, db.RetryJobParams{
+		JobExternalID: job.JobExternalID,
+		RunAt:         pgtype.Timestamptz{Time: time.Now().Add(delay), Valid: true},
+		LastError:     lastError,
+	})
+}
+
+// backoff doubles from baseBackoff per attempt, capped at maxBackoff, with up
+// to 20% jitter so retries from a burst of failures spread out.
+func backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// maintain puts back jobs whose worker died mid-run and prunes old
+// successful jobs. Dead jobs are kept for inspection.
+func (w *Worker) maintain(ctx context.Context) {
+	ticker := time.NewTicker(maintenancePeriod)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		if err := w.store.Querier.RequeueStaleJobs(ctx, pgtype.Timestamptz{Time: now.Add(-staleAfter), Valid: true}); err != nil {
+			fmt.Printf("Cannot requeue stale jobs: %v\n", err)
+		}
+		if err := w.store.Querier.DeleteFinishedJobs(ctx, pgtype.Timestamptz{Time: now.Add(-keepFinishedFor), Valid: true}); err != nil {
+			fmt.Printf("Cannot delete finished jobs: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 	"github.com/zahra-pzk/Chatbot_Project3/ai"
-	"github.com/zahra-pzk/Chatbot_Project3/api"
+	"github.com/zahra-pzk/Chatbot_Project3/api/route"
+	"github.com/zahra-pzk/Chatbot_Project3/api/ws"
 	db "github.com/zahra-pzk/Chatbot_Project3/db/sqlc"
+	"github.com/zahra-pzk/Chatbot_Project3/jobs"
+	"github.com/zahra-pzk/Chatbot_Project3/storage"
 	"github.com/zahra-pzk/Chatbot_Project3/util"
 )
 
@@ -25,15 +28,52 @@ func main() {
 		log.Fatal("cannot connect to db:", err)
 	}
 
-	err = ai.CreateVectorStore(ctx, pool, "data.txt")
+	vectorStore, err := ai.NewVectorStore(ctx, config, pool)
+	if err != nil {
+		log.Fatal("cannot create vector store:", err)
+	}
+
+	store := db.NewStore(pool)
+	embeddings, err := ai.NewEmbeddingService(config, store)
+	if err != nil {
+		log.Fatal("cannot create embedding service:", err)
+	}
+	go embeddings.Start(context.Background())
+
+	report, err := ai.CreateVectorStore(ctx, config, embeddings, vectorStore, "data.txt", ai.IngestOptions{})
 	if err != nil {
 		log.Printf("Warning: cannot create vector store (maybe file missing?): %v", err)
+	} else {
+		log.Printf("data.txt ingested: %s", report)
 	}
 
-	go ai.StartBot(context.Background(), pool)
+	hub := ws.NewHub()
+	go hub.Run()
 
-	store := db.NewStore(pool)
-	server, err := api.NewServer(config, store)
+	bot, err := ai.NewBot(config, pool, store, hub, vectorStore, embeddings)
+	if err != nil {
+		log.Fatal("cannot create bot:", err)
+	}
+	go bot.Start(context.Background())
+
+	files, err := storage.NewStorage(config)
+	if err != nil {
+		log.Fatal("cannot create storage:", err)
+	}
+	jobQueue := jobs.NewQueue(store)
+	jobWorker := jobs.NewWorker(config, store)
+	documents := ai.NewDocumentProcessor(config, store, files, vectorStore, embeddings, jobQueue)
+	documents.Register(jobWorker)
+	go jobWorker.Start(context.Background())
+
+	prompts, err := ai.NewPromptPreviewer(config, store, vectorStore, embeddings)
+	if err != nil {
+		log.Fatal("cannot create prompt previewer:", err)
+	}
+
+	knowledge := ai.NewKnowledgeBase(config, store, vectorStore, embeddings)
+
+	server, err := route.NewServer(config, store, hub, files, documents, prompts, embeddings, knowledge)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -42,4 +82,4 @@ func main() {
 	if err := server.Start(config.ServerAddress); err != nil {
 		log.Fatal("cannot start server:", err)
 	}
-}
\ No newline at end of file
+}
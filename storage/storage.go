@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zahra-pzk/Chatbot_Project3/util"
+)
+
+const (
+	BackendLocal = "local"
+
+	defaultLocalDir = "data/documents"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded documents. Keys are slash separated and chosen by
+// the caller; backends must not interpret them beyond that.
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func NewStorage(config util.Config) (Storage, error) {
+	switch strings.ToLower(strings.TrimSpace(config.StorageBackend)) {
+	case "", BackendLocal:
+		dir := config.StorageDir
+		if dir == "" {
+			dir = defaultLocalDir
+		}
+		return NewLocalStorage(dir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.StorageBackend)
+	}
+}
@@ -7,19 +7,48 @@ import (
 )
 
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	AIBaseURL           string        `mapstructure:"AIBaseURL"`
-	AIAPIKey            string        `mapstructure:"AI_API_KEY"`
-	APIURL              string        `mapstructure:"API_URL"`
-	WS_URL              string        `mapstructure:"WS_URL"`
-	BotUsername         string        `mapstructure:"BOT_USERNAME"`
-	BotPass             string        `mapstructure:"BOT_PASSWORD"`
-	ChunkSize           int64         `mapstructure:"ChunkSize"`
-	ChunkOverlap        int64         `mapstructure:"ChunkOverlap"`
+	DBDriver                string        `mapstructure:"DB_DRIVER"`
+	DBSource                string        `mapstructure:"DB_SOURCE"`
+	ServerAddress           string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey       string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration     time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	AIBaseURL               string        `mapstructure:"AIBaseURL"`
+	AIAPIKey                string        `mapstructure:"AI_API_KEY"`
+	BotUsername             string        `mapstructure:"BOT_USERNAME"`
+	ChunkSize               int64         `mapstructure:"ChunkSize"`
+	ChunkOverlap            int64         `mapstructure:"ChunkOverlap"`
+	ChunkUnit               string        `mapstructure:"CHUNK_UNIT"`
+	AIProvider              string        `mapstructure:"AI_PROVIDER"`
+	AIEmbeddingProvider     string        `mapstructure:"AI_EMBEDDING_PROVIDER"`
+	AIChatModel             string        `mapstructure:"AI_CHAT_MODEL"`
+	AIEmbeddingModel        string        `mapstructure:"AI_EMBEDDING_MODEL"`
+	AITemperature           float64       `mapstructure:"AI_TEMPERATURE"`
+	AIEmbeddingDims         int           `mapstructure:"AI_EMBEDDING_DIMENSIONS"`
+	AIContextTokens         int           `mapstructure:"AI_CONTEXT_TOKENS"`
+	AIMaxAnswerTokens       int           `mapstructure:"AI_MAX_ANSWER_TOKENS"`
+	AIMaxRetries            int           `mapstructure:"AI_MAX_RETRIES"`
+	AIBreakerThreshold      int           `mapstructure:"AI_BREAKER_THRESHOLD"`
+	AIBreakerCooldown       time.Duration `mapstructure:"AI_BREAKER_COOLDOWN"`
+	EmbeddingBatchSize      int           `mapstructure:"EMBEDDING_BATCH_SIZE"`
+	EmbeddingCacheTTL       time.Duration `mapstructure:"EMBEDDING_CACHE_TTL"`
+	VectorStore             string        `mapstructure:"VECTOR_STORE"`
+	RetrievalVectorWeight   float64       `mapstructure:"RETRIEVAL_VECTOR_WEIGHT"`
+	RetrievalTextWeight     float64       `mapstructure:"RETRIEVAL_TEXT_WEIGHT"`
+	RetrievalRRFK           int           `mapstructure:"RETRIEVAL_RRF_K"`
+	RetrievalTopK           int           `mapstructure:"RETRIEVAL_TOP_K"`
+	RetrievalFeedbackWeight float64       `mapstructure:"RETRIEVAL_FEEDBACK_WEIGHT"`
+	BotHistoryMessages      int           `mapstructure:"BOT_HISTORY_MESSAGES"`
+	BotDefaultLanguage      string        `mapstructure:"BOT_DEFAULT_LANGUAGE"`
+	BotLanguages            []string      `mapstructure:"BOT_LANGUAGES"`
+	BotTools                []string      `mapstructure:"BOT_TOOLS"`
+	BotConcurrency          int           `mapstructure:"BOT_CONCURRENCY"`
+	CompanyName             string        `mapstructure:"COMPANY_NAME"`
+	HandoffMinSimilarity    float64       `mapstructure:"HANDOFF_MIN_SIMILARITY"`
+	HandoffPhrases          []string      `mapstructure:"HANDOFF_PHRASES"`
+	StorageBackend          string        `mapstructure:"STORAGE_BACKEND"`
+	StorageDir              string        `mapstructure:"STORAGE_DIR"`
+	JobWorkers              int           `mapstructure:"JOB_WORKERS"`
+	JobPollInterval         time.Duration `mapstructure:"JOB_POLL_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {